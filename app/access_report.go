@@ -0,0 +1,406 @@
+package app
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+// The access report combines three APIs:
+//
+//	// TeamAccesses.List all the team accesses for a given workspace.
+//	List(ctx context.Context, options *TeamAccessListOptions) (*TeamAccessList, error)
+//
+//	// Teams.List all the teams of the given organization, including their organization access.
+//	List(ctx context.Context, organization string, options *TeamListOptions) (*TeamList, error)
+//
+//	// TeamMembers.ListUsers returns the users that are members of the team.
+//	ListUsers(ctx context.Context, teamID string) ([]*User, error)
+
+func (tfc *TFCClient) AccessReportCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "report",
+		Usage:    "Report the effective access of every team on every selected workspace, and the workspaces each user can write to or administer.",
+		Category: "team access",
+		Action:   tfc.accessReport,
+		Flags: []cli.Flag{
+			selectorFlag(),
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Usage:   "json, csv or markdown",
+				Value:   "json",
+			},
+			&cli.StringFlag{
+				Name:  "view",
+				Usage: "csv and markdown only: matrix prints teams by workspaces, users prints each user's write and admin access.",
+				Value: "matrix",
+			},
+			concurrencyFlag(),
+		},
+	}
+}
+
+type accessReportGrant struct {
+	Workspace   string
+	WorkspaceID string
+	Team        string
+	TeamID      string
+	Access      string
+	Permissions string `json:",omitempty"`
+	// Source is workspace for a team access grant, or organization when the team's organization
+	// access (owners, manage workspaces) gives it admin on every workspace.
+	Source string
+}
+
+type accessReportUserAccess struct {
+	Workspace string
+	Access    string
+	Team      string
+}
+
+type accessReportUser struct {
+	Username   string `json:",omitempty"`
+	Email      string `json:",omitempty"`
+	Teams      []string
+	Workspaces []accessReportUserAccess `json:",omitempty"`
+}
+
+type accessReportResponse struct {
+	Teams      []teamResponse
+	Workspaces []string
+	Grants     []accessReportGrant
+	Users      []*accessReportUser
+}
+
+func (tfc *TFCClient) accessReport(ctx *cli.Context) error {
+	format, view := ctx.String("format"), ctx.String("view")
+	switch format {
+	case "json", "csv", "markdown":
+	default:
+		return fmt.Errorf("format not recognized: %s", format)
+	}
+	if view != "matrix" && view != "users" {
+		return fmt.Errorf("view not recognized: %s", view)
+	}
+
+	teams, err := tfc.listTeams(ctx.Context, nil)
+	if err != nil {
+		return err
+	}
+
+	workspaces, err := tfc.selectWorkspaces(ctx.Context, ctx.StringSlice("selector"))
+	if err != nil {
+		return err
+	}
+
+	logf(ctx, "reading the members of %d teams and the team access of %d workspaces", len(teams), len(workspaces))
+
+	report := &accessReportResponse{Teams: make([]teamResponse, len(teams))}
+	teamNames := make(map[string]string, len(teams))
+	for i, t := range teams {
+		report.Teams[i] = newTeamResponse(t)
+		teamNames[t.ID] = t.Name
+	}
+
+	indexes := make([]int, len(report.Teams))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	err = forEachParallel(ctx.Context, ctx.Int("concurrency"), indexes, func(c context.Context, i int) error {
+		members, err := tfc.teamMembers(c, report.Teams[i].ID)
+		if err != nil {
+			return fmt.Errorf("listing members of team %s: %w", report.Teams[i].Name, err)
+		}
+		report.Teams[i].Members = members
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var (
+		mu     sync.Mutex
+		failed int
+	)
+
+	err = forEachParallel(ctx.Context, ctx.Int("concurrency"), workspaces, func(c context.Context, ws *tfe.Workspace) error {
+		accesses, err := tfc.listTeamAccess(c, ws.ID)
+		if err != nil {
+			mu.Lock()
+			logf(ctx, "%s: %s", ws.Name, err)
+			failed++
+			mu.Unlock()
+			return nil
+		}
+
+		grants := effectiveGrants(ws, teams, accesses, teamNames)
+
+		mu.Lock()
+		defer mu.Unlock()
+		report.Workspaces = append(report.Workspaces, ws.Name)
+		report.Grants = append(report.Grants, grants...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(report.Workspaces)
+	sort.Slice(report.Grants, func(i, j int) bool {
+		a, b := report.Grants[i], report.Grants[j]
+		if a.Workspace != b.Workspace {
+			return a.Workspace < b.Workspace
+		}
+		return a.Team < b.Team
+	})
+
+	report.Users = accessReportUsers(report.Teams, report.Grants)
+
+	switch {
+	case format == "csv" && view == "matrix":
+		err = writeAccessMatrixCSV(report)
+	case format == "csv":
+		err = writeAccessUsersCSV(report)
+	case format == "markdown" && view == "matrix":
+		writeAccessMatrixMarkdown(report)
+	case format == "markdown":
+		writeAccessUsersMarkdown(report)
+	default:
+		var r []byte
+		if r, err = json.MarshalIndent(report, "", "    "); err == nil {
+			fmt.Println(string(r))
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to read the team access of %d workspaces, they are missing from the report", failed)
+	}
+	return nil
+}
+
+// effectiveGrants returns every team's access on a workspace. Teams whose organization access lets
+// them manage workspaces are admins of every workspace, whatever their workspace grant says.
+func effectiveGrants(ws *tfe.Workspace, teams []*tfe.Team, accesses []*tfe.TeamAccess, teamNames map[string]string) []accessReportGrant {
+	orgAdmins := map[string]bool{}
+	var grants []accessReportGrant
+
+	for _, t := range teams {
+		if t.Name == "owners" || (t.OrganizationAccess != nil && t.OrganizationAccess.ManageWorkspaces) {
+			orgAdmins[t.ID] = true
+			grants = append(grants, accessReportGrant{
+				Workspace:   ws.Name,
+				WorkspaceID: ws.ID,
+				Team:        t.Name,
+				TeamID:      t.ID,
+				Access:      string(tfe.AccessAdmin),
+				Source:      "organization",
+			})
+		}
+	}
+
+	for _, ta := range accesses {
+		if ta.Team == nil || orgAdmins[ta.Team.ID] {
+			continue
+		}
+
+		g := accessReportGrant{
+			Workspace:   ws.Name,
+			WorkspaceID: ws.ID,
+			Team:        teamNames[ta.Team.ID],
+			TeamID:      ta.Team.ID,
+			Access:      string(ta.Access),
+			Source:      "workspace",
+		}
+
+		if ta.Access == tfe.AccessCustom {
+			g.Permissions = customPermissions(ta)
+		}
+
+		grants = append(grants, g)
+	}
+
+	return grants
+}
+
+func customPermissions(ta *tfe.TeamAccess) string {
+	return fmt.Sprintf("runs=%s variables=%s state-versions=%s sentinel-mocks=%s workspace-locking=%t run-tasks=%t",
+		ta.Runs, ta.Variables, ta.StateVersions, ta.SentinelMocks, ta.WorkspaceLocking, ta.RunTasks)
+}
+
+// grantsWrite reports whether a grant lets its team change the workspace's infrastructure or
+// configuration: write and admin access, or custom access that can apply runs or write variables or state.
+func grantsWrite(g accessReportGrant) bool {
+	switch tfe.AccessType(g.Access) {
+	case tfe.AccessWrite, tfe.AccessAdmin:
+		return true
+	case tfe.AccessCustom:
+		return strings.Contains(g.Permissions, "runs=apply") ||
+			strings.Contains(g.Permissions, "variables=write") ||
+			strings.Contains(g.Permissions, "state-versions=write")
+	}
+	return false
+}
+
+// accessReportUsers expands the write and admin grants to the members of each team.
+func accessReportUsers(teams []teamResponse, grants []accessReportGrant) []*accessReportUser {
+	byTeam := map[string][]accessReportGrant{}
+	for _, g := range grants {
+		if grantsWrite(g) {
+			byTeam[g.TeamID] = append(byTeam[g.TeamID], g)
+		}
+	}
+
+	users := map[string]*accessReportUser{}
+	for _, t := range teams {
+		for _, m := range t.Members {
+			key := m.UserID
+			if key == "" {
+				key = m.Email
+			}
+
+			u, ok := users[key]
+			if !ok {
+				u = &accessReportUser{Username: m.Username, Email: m.Email}
+				users[key] = u
+			}
+
+			u.Teams = append(u.Teams, t.Name)
+			for _, g := range byTeam[t.ID] {
+				u.Workspaces = append(u.Workspaces, accessReportUserAccess{Workspace: g.Workspace, Access: g.Access, Team: t.Name})
+			}
+		}
+	}
+
+	r := make([]*accessReportUser, 0, len(users))
+	for _, u := range users {
+		sort.Strings(u.Teams)
+		sort.Slice(u.Workspaces, func(i, j int) bool {
+			a, b := u.Workspaces[i], u.Workspaces[j]
+			if a.Workspace != b.Workspace {
+				return a.Workspace < b.Workspace
+			}
+			return a.Team < b.Team
+		})
+		r = append(r, u)
+	}
+
+	sort.Slice(r, func(i, j int) bool { return r[i].Username+r[i].Email < r[j].Username+r[j].Email })
+	return r
+}
+
+// accessMatrix returns the team names as columns and one row of access levels per workspace.
+func accessMatrix(report *accessReportResponse) ([]string, [][]string) {
+	cells := map[string]map[string]string{}
+	for _, g := range report.Grants {
+		if cells[g.Workspace] == nil {
+			cells[g.Workspace] = map[string]string{}
+		}
+		cells[g.Workspace][g.Team] = g.Access
+		if g.Source == "organization" {
+			cells[g.Workspace][g.Team] += " (org)"
+		}
+	}
+
+	columns := make([]string, len(report.Teams))
+	for i, t := range report.Teams {
+		columns[i] = t.Name
+	}
+	sort.Strings(columns)
+
+	rows := make([][]string, len(report.Workspaces))
+	for i, ws := range report.Workspaces {
+		rows[i] = append(rows[i], ws)
+		for _, team := range columns {
+			rows[i] = append(rows[i], cells[ws][team])
+		}
+	}
+
+	return columns, rows
+}
+
+func writeAccessMatrixCSV(report *accessReportResponse) error {
+	columns, rows := accessMatrix(report)
+	w := csv.NewWriter(os.Stdout)
+
+	if err := w.Write(append([]string{"workspace"}, columns...)); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func writeAccessUsersCSV(report *accessReportResponse) error {
+	w := csv.NewWriter(os.Stdout)
+
+	if err := w.Write([]string{"username", "email", "teams", "workspace", "access", "team"}); err != nil {
+		return err
+	}
+
+	for _, u := range report.Users {
+		teams := strings.Join(u.Teams, " ")
+		if len(u.Workspaces) == 0 {
+			if err := w.Write([]string{u.Username, u.Email, teams, "", "", ""}); err != nil {
+				return err
+			}
+		}
+		for _, a := range u.Workspaces {
+			if err := w.Write([]string{u.Username, u.Email, teams, a.Workspace, a.Access, a.Team}); err != nil {
+				return err
+			}
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func writeAccessMatrixMarkdown(report *accessReportResponse) {
+	columns, rows := accessMatrix(report)
+
+	fmt.Printf("## Team access\n\n| Workspace | %s |\n|%s\n", strings.Join(columns, " | "), strings.Repeat(" --- |", len(columns)+1))
+	for _, row := range rows {
+		fmt.Printf("| %s |\n", strings.Join(row, " | "))
+	}
+	fmt.Println()
+
+	fmt.Printf("## Team members\n\n| Team | Members |\n| --- | --- |\n")
+	for _, t := range report.Teams {
+		names := make([]string, len(t.Members))
+		for i, m := range t.Members {
+			names[i] = m.Username
+			if names[i] == "" {
+				names[i] = m.Email
+			}
+		}
+		fmt.Printf("| %s | %s |\n", t.Name, strings.Join(names, ", "))
+	}
+}
+
+func writeAccessUsersMarkdown(report *accessReportResponse) {
+	fmt.Printf("## Write and admin access by user\n\n| User | Email | Workspace | Access | Through team |\n| --- | --- | --- | --- | --- |\n")
+	for _, u := range report.Users {
+		for _, a := range u.Workspaces {
+			fmt.Printf("| %s | %s | %s | %s | %s |\n", u.Username, u.Email, a.Workspace, a.Access, a.Team)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+func (tfc *TFCClient) GraphWorkspacesCmd() *cli.Command {
+	return &cli.Command{
+		Name: "workspaces",
+		Usage: "Graph how the selected workspaces depend on each other through run triggers, remote state consumers and shared variable sets. " +
+			"Workspaces without any of these are marked as orphans, and workspaces in a dependency cycle are highlighted.",
+		Category: "graph",
+		Action:   tfc.graphWorkspaces,
+		Flags: []cli.Flag{
+			selectorFlag(),
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Usage:   "dot, mermaid or json",
+				Value:   "dot",
+			},
+			&cli.BoolFlag{
+				Name:  "var-sets",
+				Usage: "Include the variable sets shared by the workspaces. Global variable sets are left out.",
+				Value: true,
+			},
+			concurrencyFlag(),
+		},
+	}
+}
+
+type graphLink struct {
+	Workspace string
+	Kind      string
+}
+
+type graphNode struct {
+	ID           string
+	Name         string
+	Selected     bool
+	Orphan       bool
+	InCycle      bool
+	Upstream     []graphLink `json:",omitempty"`
+	Downstream   []graphLink `json:",omitempty"`
+	VariableSets []string    `json:",omitempty"`
+}
+
+type graphResponse struct {
+	Workspaces []*graphNode
+	Cycles     [][]string `json:",omitempty"`
+}
+
+func (tfc *TFCClient) graphWorkspaces(ctx *cli.Context) error {
+	format := ctx.String("format")
+	switch format {
+	case "dot", "mermaid", "json":
+	default:
+		return fmt.Errorf("format not recognized: %s", format)
+	}
+
+	all, err := tfc.listWorkspaces(ctx.Context, &tfe.WorkspaceListOptions{})
+	if err != nil {
+		return err
+	}
+
+	selected, err := tfc.selectWorkspaces(ctx.Context, ctx.StringSlice("selector"))
+	if err != nil {
+		return err
+	}
+
+	logf(ctx, "reading the run triggers and remote state consumers of %d workspaces", len(selected))
+
+	g, err := tfc.buildWorkspaceGraph(ctx.Context, ctx.Int("concurrency"), selected, all, false)
+	if err != nil {
+		return err
+	}
+
+	// Outbound edges alone would leave out the run trigger sources of the selected workspaces
+	if err := tfc.addInboundTriggers(ctx.Context, ctx.Int("concurrency"), g, selected, all); err != nil {
+		return err
+	}
+
+	response := &graphResponse{}
+	nodes := make(map[string]*graphNode, len(g.Workspaces))
+	for id := range g.Workspaces {
+		nodes[id] = &graphNode{ID: id, Name: g.name(id)}
+	}
+	for _, ws := range selected {
+		nodes[ws.ID].Selected = true
+	}
+
+	for _, e := range g.Edges {
+		nodes[e.From].Downstream = append(nodes[e.From].Downstream, graphLink{Workspace: g.name(e.To), Kind: e.Kind})
+		nodes[e.To].Upstream = append(nodes[e.To].Upstream, graphLink{Workspace: g.name(e.From), Kind: e.Kind})
+	}
+
+	if ctx.Bool("var-sets") {
+		varSets, err := tfc.listVarSets(ctx.Context, []tfe.VariableSetIncludeOpt{tfe.VariableSetWorkspaces})
+		if err != nil {
+			return err
+		}
+
+		for _, vs := range varSets {
+			if vs.Global {
+				continue
+			}
+			for _, ws := range vs.Workspaces {
+				if n, ok := nodes[ws.ID]; ok {
+					n.VariableSets = append(n.VariableSets, vs.Name)
+				}
+			}
+		}
+	}
+
+	for _, c := range g.cycles() {
+		names := make([]string, len(c))
+		for i, id := range c {
+			nodes[id].InCycle = true
+			names[i] = g.name(id)
+		}
+		response.Cycles = append(response.Cycles, names)
+	}
+
+	for _, n := range nodes {
+		sort.Strings(n.VariableSets)
+		response.Workspaces = append(response.Workspaces, n)
+	}
+	sort.Slice(response.Workspaces, func(i, j int) bool { return response.Workspaces[i].Name < response.Workspaces[j].Name })
+
+	// A variable set only connects workspaces when it's shared by more than one of them
+	shared, _ := sharedVarSets(response)
+	isShared := map[string]bool{}
+	for _, vs := range shared {
+		isShared[vs] = true
+	}
+
+	for _, n := range response.Workspaces {
+		n.Orphan = len(n.Upstream) == 0 && len(n.Downstream) == 0
+		for _, vs := range n.VariableSets {
+			n.Orphan = n.Orphan && !isShared[vs]
+		}
+	}
+
+	switch format {
+	case "dot":
+		fmt.Print(graphDOT(g, response))
+	case "mermaid":
+		fmt.Print(graphMermaid(g, response))
+	default:
+		r, err := json.MarshalIndent(response, "", "    ")
+		if err != nil {
+			return nil
+		}
+		fmt.Println(string(r))
+	}
+
+	return nil
+}
+
+// sharedVarSets returns the variable sets attached to more than one workspace in the graph,
+// with the workspaces each one is attached to.
+func sharedVarSets(response *graphResponse) ([]string, map[string][]*graphNode) {
+	members := map[string][]*graphNode{}
+	for _, n := range response.Workspaces {
+		for _, vs := range n.VariableSets {
+			members[vs] = append(members[vs], n)
+		}
+	}
+
+	var names []string
+	for vs, ns := range members {
+		if len(ns) > 1 {
+			names = append(names, vs)
+		}
+	}
+	sort.Strings(names)
+
+	return names, members
+}
+
+func graphDOT(g *workspaceGraph, response *graphResponse) string {
+	quote := func(s string) string { return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"` }
+
+	var b strings.Builder
+	b.WriteString("digraph workspaces {\n\trankdir=LR;\n\tnode [shape=box];\n")
+
+	for _, n := range response.Workspaces {
+		var attrs []string
+		switch {
+		case n.InCycle:
+			attrs = append(attrs, "color=red", "fontcolor=red")
+		case n.Orphan:
+			attrs = append(attrs, "style=dashed", "color=gray", "fontcolor=gray")
+		}
+		if !n.Selected {
+			attrs = append(attrs, "shape=note")
+		}
+
+		fmt.Fprintf(&b, "\t%s", quote(n.Name))
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(attrs, ","))
+		}
+		b.WriteString(";\n")
+	}
+
+	for _, e := range g.Edges {
+		style := ""
+		if e.Kind == edgeRemoteState {
+			style = ",style=dashed"
+		}
+		fmt.Fprintf(&b, "\t%s -> %s [label=%s%s];\n", quote(g.name(e.From)), quote(g.name(e.To)), quote(e.Kind), style)
+	}
+
+	names, members := sharedVarSets(response)
+	for _, vs := range names {
+		id := quote("varset: " + vs)
+		fmt.Fprintf(&b, "\t%s [shape=ellipse,color=blue,fontcolor=blue];\n", id)
+		for _, n := range members[vs] {
+			fmt.Fprintf(&b, "\t%s -> %s [dir=none,style=dotted,color=blue];\n", id, quote(n.Name))
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func graphMermaid(g *workspaceGraph, response *graphResponse) string {
+	// Mermaid node ids can't contain most punctuation, so nodes are numbered and labelled
+	ids := make(map[string]string, len(response.Workspaces))
+	label := func(s string) string { return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"` }
+
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	b.WriteString("\tclassDef orphan stroke-dasharray: 5 5,color:#888\n")
+	b.WriteString("\tclassDef cycle stroke:#d00,color:#d00\n")
+
+	var orphans, cyclic []string
+	for i, n := range response.Workspaces {
+		id := fmt.Sprintf("w%d", i)
+		ids[n.ID] = id
+		fmt.Fprintf(&b, "\t%s[%s]\n", id, label(n.Name))
+
+		switch {
+		case n.InCycle:
+			cyclic = append(cyclic, id)
+		case n.Orphan:
+			orphans = append(orphans, id)
+		}
+	}
+
+	for _, e := range g.Edges {
+		arrow := "-->"
+		if e.Kind == edgeRemoteState {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "\t%s %s|%s| %s\n", ids[e.From], arrow, e.Kind, ids[e.To])
+	}
+
+	names, members := sharedVarSets(response)
+	for i, vs := range names {
+		id := fmt.Sprintf("v%d", i)
+		fmt.Fprintf(&b, "\t%s([%s])\n", id, label("varset: "+vs))
+		for _, n := range members[vs] {
+			fmt.Fprintf(&b, "\t%s --- %s\n", id, ids[n.ID])
+		}
+	}
+
+	if len(orphans) > 0 {
+		fmt.Fprintf(&b, "\tclass %s orphan\n", strings.Join(orphans, ","))
+	}
+	if len(cyclic) > 0 {
+		fmt.Fprintf(&b, "\tclass %s cycle\n", strings.Join(cyclic, ","))
+	}
+
+	return b.String()
+}
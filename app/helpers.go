@@ -1,6 +1,19 @@
 package app
 
-import "github.com/urfave/cli/v2"
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
 
 func ptrString(v string) *string {
 	if v == "" {
@@ -28,3 +41,123 @@ func getIfSetString(ctx *cli.Context, key string) *string {
 	r := ctx.String(key)
 	return &r
 }
+
+// readInput reads the named file, or stdin when path is "-".
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// readLines reads one entry per line from the named file, or stdin when path is "-".
+// Blank lines and lines starting with # are ignored.
+func readLines(path string) ([]string, error) {
+	b, err := readInput(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, s.Err()
+}
+
+func parseCategory(v string) (tfe.CategoryType, error) {
+	switch c := tfe.CategoryType(v); c {
+	case tfe.CategoryTerraform, tfe.CategoryEnv:
+		return c, nil
+	default:
+		return "", fmt.Errorf("category not recognized: %s; expected terraform or env", v)
+	}
+}
+
+// forEachParallel calls fn for every item with at most n calls in flight. The first error
+// cancels the context handed to the remaining calls and is returned once they finish.
+func forEachParallel[T any](ctx context.Context, n int, items []T, fn func(context.Context, T) error) error {
+	if n < 1 {
+		n = 1
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+		sem   = make(chan struct{}, n)
+	)
+
+loop:
+	for _, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break loop
+		}
+
+		wg.Add(1)
+		go func(item T) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := fn(ctx, item); err != nil {
+				once.Do(func() {
+					first = err
+					cancel()
+				})
+			}
+		}(item)
+	}
+
+	wg.Wait()
+
+	if first != nil {
+		return first
+	}
+	return parent.Err()
+}
+
+// logf prints progress to stderr when --verbose is set, keeping stdout clean for command output.
+func logf(ctx *cli.Context, format string, a ...interface{}) {
+	if ctx.Bool("verbose") {
+		fmt.Fprintf(os.Stderr, format+"\n", a...)
+	}
+}
+
+// resourceIDPattern matches Terraform Cloud resource IDs: a type prefix followed by 16 base58 characters.
+var resourceIDPattern = regexp.MustCompile(`^[a-z]+-[1-9A-HJ-NP-Za-km-z]{16}$`)
+
+// looksLikeID reports whether s has the form of a Terraform Cloud ID with the given prefix,
+// e.g. "ws-" or "team-". Names can start with the same prefix, so callers that read by ID
+// should still fall back to a name lookup when the ID isn't found.
+func looksLikeID(prefix, s string) bool {
+	return strings.HasPrefix(s, prefix) && resourceIDPattern.MatchString(s)
+}
+
+// isTerminal reports whether f is a terminal rather than a file or pipe.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// globMatch reports whether s matches pattern, where * matches any run of characters and
+// everything else is literal. Unlike path.Match, brackets and dots in resource addresses
+// are not special.
+func globMatch(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$").MatchString(s)
+}
@@ -0,0 +1,277 @@
+package app
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/hashicorp/go-version"
+	"github.com/urfave/cli/v2"
+)
+
+func (tfc *TFCClient) InventoryVersionsCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "versions",
+		Usage:    "Report the Terraform versions and providers used by every selected workspace. State doesn't record provider versions, so providers are listed by address.",
+		Category: "inventory",
+		Action:   tfc.inventoryVersions,
+		Flags: []cli.Flag{
+			selectorFlag(),
+			&cli.StringFlag{
+				Name:    "constraint",
+				Aliases: []string{"c"},
+				Usage:   "Terraform version constraint, e.g. \">= 1.5.0\". Workspaces whose version doesn't satisfy it are reported as lagging.",
+			},
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Usage:   "json, csv or markdown. csv and markdown print an upgrade worklist of the lagging workspaces, or every workspace without --constraint.",
+				Value:   "json",
+			},
+			concurrencyFlag(),
+		},
+	}
+}
+
+type versionInventoryEntry struct {
+	Workspace             string
+	WorkspaceID           string
+	TerraformVersion      string
+	StateTerraformVersion string   `json:",omitempty"`
+	Providers             []string `json:",omitempty"`
+	Lagging               bool
+	Reason                string `json:",omitempty"`
+}
+
+type versionInventoryResponse struct {
+	Constraint             string `json:",omitempty"`
+	TerraformVersions      map[string]int
+	StateTerraformVersions map[string]int
+	Providers              map[string]int
+	Workspaces             []*versionInventoryEntry
+}
+
+func (tfc *TFCClient) inventoryVersions(ctx *cli.Context) error {
+	format := ctx.String("format")
+	switch format {
+	case "json", "csv", "markdown":
+	default:
+		return fmt.Errorf("format not recognized: %s", format)
+	}
+
+	var constraint version.Constraints
+	if ctx.IsSet("constraint") {
+		c, err := version.NewConstraint(ctx.String("constraint"))
+		if err != nil {
+			return fmt.Errorf("invalid constraint: %w", err)
+		}
+		constraint = c
+	}
+
+	workspaces, err := tfc.selectWorkspaces(ctx.Context, ctx.StringSlice("selector"))
+	if err != nil {
+		return err
+	}
+
+	logf(ctx, "reading the current state of %d workspaces", len(workspaces))
+
+	var (
+		mu      sync.Mutex
+		entries []*versionInventoryEntry
+		failed  int
+	)
+
+	err = forEachParallel(ctx.Context, ctx.Int("concurrency"), workspaces, func(c context.Context, ws *tfe.Workspace) error {
+		e := &versionInventoryEntry{
+			Workspace:        ws.Name,
+			WorkspaceID:      ws.ID,
+			TerraformVersion: ws.TerraformVersion,
+		}
+
+		state, _, err := tfc.currentState(c, ws)
+		switch {
+		case errors.Is(err, tfe.ErrResourceNotFound):
+		case err != nil:
+			mu.Lock()
+			logf(ctx, "%s: %s", ws.Name, err)
+			failed++
+			mu.Unlock()
+		default:
+			e.StateTerraformVersion = state.TerraformVersion
+
+			providers := map[string]bool{}
+			for _, r := range state.Resources {
+				providers[providerWithoutAlias(providerSource(r.Provider))] = true
+			}
+			for p := range providers {
+				e.Providers = append(e.Providers, p)
+			}
+			sort.Strings(e.Providers)
+		}
+
+		if constraint != nil {
+			e.Lagging, e.Reason = lagsConstraint(constraint, e.TerraformVersion, e.StateTerraformVersion)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Workspace < entries[j].Workspace })
+
+	inv := &versionInventoryResponse{
+		Constraint:             ctx.String("constraint"),
+		TerraformVersions:      map[string]int{},
+		StateTerraformVersions: map[string]int{},
+		Providers:              map[string]int{},
+		Workspaces:             entries,
+	}
+
+	for _, e := range entries {
+		inv.TerraformVersions[e.TerraformVersion]++
+		if e.StateTerraformVersion != "" {
+			inv.StateTerraformVersions[e.StateTerraformVersion]++
+		}
+		for _, p := range e.Providers {
+			inv.Providers[p]++
+		}
+	}
+
+	var worklist []*versionInventoryEntry
+	for _, e := range entries {
+		if constraint == nil || e.Lagging {
+			worklist = append(worklist, e)
+		}
+	}
+
+	switch format {
+	case "csv":
+		err = writeVersionWorklistCSV(worklist)
+	case "markdown":
+		writeVersionInventoryMarkdown(inv, worklist)
+	default:
+		var r []byte
+		if r, err = json.MarshalIndent(inv, "", "    "); err == nil {
+			fmt.Println(string(r))
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to read the state of %d workspaces, their state versions are missing from the report", failed)
+	}
+	return nil
+}
+
+// lagsConstraint checks the workspace's Terraform version setting, when it's an exact version,
+// and the version that last wrote its state against the constraint.
+func lagsConstraint(c version.Constraints, setting, state string) (bool, string) {
+	var reasons []string
+
+	if v, err := version.NewVersion(setting); err == nil && !c.Check(v) {
+		reasons = append(reasons, fmt.Sprintf("workspace uses terraform %s", setting))
+	}
+
+	if v, err := version.NewVersion(state); err == nil && !c.Check(v) {
+		reasons = append(reasons, fmt.Sprintf("state written by terraform %s", state))
+	}
+
+	return len(reasons) > 0, strings.Join(reasons, "; ")
+}
+
+// providerWithoutAlias drops the alias from a provider source, e.g. registry.terraform.io/hashicorp/aws.east.
+// Legacy 0.12 addresses like provider.aws have no source to separate an alias from and are kept as is.
+func providerWithoutAlias(p string) string {
+	i := strings.LastIndex(p, "/")
+	if i < 0 {
+		return p
+	}
+	if j := strings.Index(p[i+1:], "."); j >= 0 {
+		return p[:i+1+j]
+	}
+	return p
+}
+
+func writeVersionWorklistCSV(worklist []*versionInventoryEntry) error {
+	w := csv.NewWriter(os.Stdout)
+
+	if err := w.Write([]string{"workspace", "workspace_id", "terraform_version", "state_terraform_version", "lagging", "reason", "providers"}); err != nil {
+		return err
+	}
+
+	for _, e := range worklist {
+		if err := w.Write([]string{
+			e.Workspace,
+			e.WorkspaceID,
+			e.TerraformVersion,
+			e.StateTerraformVersion,
+			fmt.Sprint(e.Lagging),
+			e.Reason,
+			strings.Join(e.Providers, " "),
+		}); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func writeVersionInventoryMarkdown(inv *versionInventoryResponse, worklist []*versionInventoryEntry) {
+	counts := func(title, column string, m map[string]int) {
+		fmt.Printf("## %s\n\n| %s | Workspaces |\n| --- | --- |\n", title, column)
+		for _, k := range sortedByCount(m) {
+			name := k
+			if name == "" {
+				name = "(unset)"
+			}
+			fmt.Printf("| %s | %d |\n", name, m[k])
+		}
+		fmt.Println()
+	}
+
+	counts("Terraform versions (workspace setting)", "Version", inv.TerraformVersions)
+	counts("Terraform versions (current state)", "Version", inv.StateTerraformVersions)
+	counts("Providers", "Provider", inv.Providers)
+
+	title := "Workspaces"
+	if inv.Constraint != "" {
+		title = fmt.Sprintf("Upgrade worklist (%s)", inv.Constraint)
+	}
+
+	fmt.Printf("## %s\n\n| Workspace | Setting | State | Reason |\n| --- | --- | --- | --- |\n", title)
+	for _, e := range worklist {
+		fmt.Printf("| %s | %s | %s | %s |\n", e.Workspace, e.TerraformVersion, e.StateTerraformVersion, e.Reason)
+	}
+}
+
+// sortedByCount returns the keys of m with the largest counts first.
+func sortedByCount(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if m[keys[i]] != m[keys[j]] {
+			return m[keys[i]] > m[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	return keys
+}
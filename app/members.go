@@ -0,0 +1,499 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+// OrganizationMemberships describes all the organization membership related methods that
+// the Terraform Enterprise API supports.
+//
+// TFE API docs: https://www.terraform.io/docs/cloud/api/organization-memberships.html
+//
+//	// List all the organization memberships of the given organization.
+//	List(ctx context.Context, organization string, options *OrganizationMembershipListOptions) (*OrganizationMembershipList, error)
+//
+//	// Create a new organization membership with the given options.
+//	Create(ctx context.Context, organization string, options OrganizationMembershipCreateOptions) (*OrganizationMembership, error)
+//
+//	// Read an organization membership by ID with options
+//	ReadWithOptions(ctx context.Context, organizationMembershipID string, options OrganizationMembershipReadOptions) (*OrganizationMembership, error)
+//
+//	// Delete an organization membership by its ID.
+//	Delete(ctx context.Context, organizationMembershipID string) error
+
+var membershipIncludes = []tfe.OrgMembershipIncludeOpt{tfe.OrgMembershipUser, tfe.OrgMembershipTeam}
+
+func (tfc *TFCClient) MembersListCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "list",
+		Aliases:  []string{"ls"},
+		Usage:    "List the organization's members and invitations.",
+		Category: "members",
+		Action:   tfc.membersList,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "status",
+				Usage: "invited or active",
+			},
+			&cli.StringSliceFlag{
+				Name:    "email",
+				Aliases: []string{"e"},
+				Usage:   "Only list members with these emails. May be repeated.",
+			},
+			&cli.StringFlag{
+				Name:    "search",
+				Aliases: []string{"s"},
+				Usage:   "Search members by username and email.",
+			},
+		},
+	}
+}
+
+type memberResponse struct {
+	ID       string
+	Email    string
+	Status   string
+	UserID   string   `json:",omitempty"`
+	Username string   `json:",omitempty"`
+	Teams    []string `json:",omitempty"`
+}
+
+func newMemberResponse(m *tfe.OrganizationMembership) memberResponse {
+	r := memberResponse{
+		ID:     m.ID,
+		Email:  m.Email,
+		Status: string(m.Status),
+	}
+
+	if m.User != nil {
+		r.UserID, r.Username = m.User.ID, m.User.Username
+	}
+
+	for _, t := range m.Teams {
+		r.Teams = append(r.Teams, t.Name)
+	}
+	sort.Strings(r.Teams)
+
+	return r
+}
+
+func (tfc *TFCClient) membersList(ctx *cli.Context) error {
+	status, err := parseMembershipStatus(ctx.String("status"))
+	if err != nil {
+		return err
+	}
+
+	memberships, err := tfc.listMemberships(ctx.Context, &tfe.OrganizationMembershipListOptions{
+		Include: membershipIncludes,
+		Emails:  ctx.StringSlice("email"),
+		Status:  status,
+		Query:   ctx.String("search"),
+	})
+	if err != nil {
+		return err
+	}
+
+	response := make([]memberResponse, len(memberships))
+	for i := range memberships {
+		response[i] = newMemberResponse(memberships[i])
+	}
+
+	r, err := json.MarshalIndent(response, "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+	return nil
+}
+
+func (tfc *TFCClient) MembersShowCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "show",
+		Usage:     "Show a member, with their teams, by email or organization membership id.",
+		UsageText: "tfc-cli members show <email|ou-...>",
+		Category:  "members",
+		Action:    tfc.membersShow,
+	}
+}
+
+func (tfc *TFCClient) membersShow(ctx *cli.Context) error {
+	m, err := tfc.resolveMembership(ctx.Context, ctx.Args().First())
+	if err != nil {
+		return err
+	}
+
+	r, err := json.MarshalIndent(newMemberResponse(m), "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+	return nil
+}
+
+func (tfc *TFCClient) MembersInviteCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "invite",
+		Usage:    "Invite users to the organization by email, optionally adding them to teams.",
+		Category: "members",
+		Action:   tfc.membersInvite,
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:     "email",
+				Aliases:  []string{"e"},
+				Usage:    "(Required) email to invite. May be repeated.",
+				Required: true,
+			},
+			&cli.StringSliceFlag{
+				Name:    "team",
+				Aliases: []string{"t"},
+				Usage:   "name or id of a team to add the invited users to. May be repeated.",
+			},
+		},
+	}
+}
+
+func (tfc *TFCClient) membersInvite(ctx *cli.Context) error {
+	// Resolve the teams first so a typo doesn't leave invitations half done
+	teams := make([]*tfe.Team, 0, len(ctx.StringSlice("team")))
+	for _, t := range ctx.StringSlice("team") {
+		team, err := tfc.resolveTeam(ctx.Context, t)
+		if err != nil {
+			return err
+		}
+		teams = append(teams, team)
+	}
+
+	var membershipIDs []string
+	for _, email := range ctx.StringSlice("email") {
+		m, err := tfc.Client.OrganizationMemberships.Create(ctx.Context, tfc.Cfg.OrgName, tfe.OrganizationMembershipCreateOptions{
+			Email: tfe.String(email),
+		})
+		if err != nil {
+			return fmt.Errorf("inviting %s: %w", email, err)
+		}
+
+		fmt.Printf("invited %s (%s)\n", email, m.ID)
+		membershipIDs = append(membershipIDs, m.ID)
+	}
+
+	for _, team := range teams {
+		if err := tfc.Client.TeamMembers.Add(ctx.Context, team.ID, tfe.TeamMemberAddOptions{OrganizationMembershipIDs: membershipIDs}); err != nil {
+			return fmt.Errorf("adding invited members to team %s: %w", team.Name, err)
+		}
+		fmt.Printf("added %d members to team %s\n", len(membershipIDs), team.Name)
+	}
+
+	return nil
+}
+
+func (tfc *TFCClient) MembersRemoveCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "remove",
+		Aliases:   []string{"rm"},
+		Usage:     "Remove a member from the organization, or cancel their invitation, by email or organization membership id.",
+		UsageText: "tfc-cli members remove <email|ou-...>",
+		Category:  "members",
+		Action:    tfc.membersRemove,
+	}
+}
+
+func (tfc *TFCClient) membersRemove(ctx *cli.Context) error {
+	m, err := tfc.resolveMembership(ctx.Context, ctx.Args().First())
+	if err != nil {
+		return err
+	}
+
+	if err := tfc.Client.OrganizationMemberships.Delete(ctx.Context, m.ID); err != nil {
+		return err
+	}
+
+	fmt.Printf("removed %s (%s) from %s\n", m.Email, m.ID, tfc.Cfg.OrgName)
+	return nil
+}
+
+func (tfc *TFCClient) MembersOffboardCmd() *cli.Command {
+	return &cli.Command{
+		Name: "offboard",
+		Usage: "Remove a user from all of their teams and the organization, and list their team and user tokens. " +
+			"The API doesn't record who created team and agent tokens, so team tokens are only revoked with --revoke-team-tokens, and only for teams the user was the last member of. " +
+			"User tokens work in every organization the user belongs to, so they are only revoked with --revoke-user-tokens.",
+		UsageText: "tfc-cli members offboard <email|ou-...> [--revoke-team-tokens] [--revoke-user-tokens] [--dry-run]",
+		Category:  "members",
+		Action:    tfc.membersOffboard,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "revoke-team-tokens",
+				Usage: "Delete the API token of every team the user was the last member of. Teams with other members keep their token.",
+			},
+			&cli.BoolFlag{
+				Name:  "revoke-user-tokens",
+				Usage: "Delete the user's own API tokens. They also stop working in every other organization the user belongs to.",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Print the changes without making them.",
+			},
+		},
+	}
+}
+
+func (tfc *TFCClient) membersOffboard(ctx *cli.Context) error {
+	m, err := tfc.resolveMembership(ctx.Context, ctx.Args().First())
+	if err != nil {
+		return err
+	}
+
+	dryRun := ctx.Bool("dry-run")
+	if dryRun {
+		fmt.Println("(dry run)")
+	}
+
+	for _, t := range m.Teams {
+		// Read the team before removing the user, to know whether anyone else still relies on its token
+		team, err := tfc.Client.Teams.Read(ctx.Context, t.ID)
+		if err != nil {
+			return fmt.Errorf("reading team %s: %w", t.Name, err)
+		}
+		t = team
+
+		if !dryRun {
+			if err := tfc.Client.TeamMembers.Remove(ctx.Context, t.ID, tfe.TeamMemberRemoveOptions{OrganizationMembershipIDs: []string{m.ID}}); err != nil {
+				return fmt.Errorf("removing %s from team %s: %w", m.Email, t.Name, err)
+			}
+		}
+		fmt.Printf("removed %s from team %s\n", m.Email, t.Name)
+
+		token, err := tfc.Client.TeamTokens.Read(ctx.Context, t.ID)
+		switch {
+		case errors.Is(err, tfe.ErrResourceNotFound):
+		case err != nil:
+			logf(ctx, "reading the token of team %s: %s", t.Name, err)
+		case ctx.Bool("revoke-team-tokens") && t.UserCount > 1:
+			fmt.Printf("team %s still has %d other members, kept its token (%s)\n", t.Name, t.UserCount-1, token.ID)
+		case ctx.Bool("revoke-team-tokens"):
+			if !dryRun {
+				if err := tfc.Client.TeamTokens.Delete(ctx.Context, t.ID); err != nil {
+					return fmt.Errorf("revoking the token of team %s: %w", t.Name, err)
+				}
+			}
+			fmt.Printf("revoked the token of team %s (%s)\n", t.Name, token.ID)
+		default:
+			fmt.Printf("team %s has a token (%s) created %s, pass --revoke-team-tokens to revoke it\n", t.Name, token.ID, token.CreatedAt.Format(time.RFC3339))
+		}
+	}
+
+	if m.User != nil {
+		// Only the user themselves, or a site admin, can see a user's tokens
+		tl, err := tfc.Client.UserTokens.List(ctx.Context, m.User.ID)
+		if err != nil {
+			logf(ctx, "the user tokens of %s aren't visible to this token: %s", m.Email, err)
+		} else {
+			for _, t := range tl.Items {
+				if !ctx.Bool("revoke-user-tokens") {
+					fmt.Printf("user token %s (%s) created %s, pass --revoke-user-tokens to revoke it in every organization\n", t.ID, t.Description, t.CreatedAt.Format(time.RFC3339))
+					continue
+				}
+
+				if !dryRun {
+					if err := tfc.Client.UserTokens.Delete(ctx.Context, t.ID); err != nil {
+						return fmt.Errorf("revoking user token %s: %w", t.ID, err)
+					}
+				}
+				fmt.Printf("revoked user token %s (%s)\n", t.ID, t.Description)
+			}
+		}
+	}
+
+	if !dryRun {
+		if err := tfc.Client.OrganizationMemberships.Delete(ctx.Context, m.ID); err != nil {
+			return fmt.Errorf("removing %s from %s: %w", m.Email, tfc.Cfg.OrgName, err)
+		}
+	}
+	fmt.Printf("removed %s (%s) from %s\n", m.Email, m.ID, tfc.Cfg.OrgName)
+
+	return nil
+}
+
+func (tfc *TFCClient) MembersPendingCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "pending",
+		Usage:    "Report invitations that haven't been accepted.",
+		Category: "members",
+		Action:   tfc.membersPending,
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "older-than",
+				Usage: "Only report invitations sent more than this many days ago.",
+			},
+		},
+	}
+}
+
+type pendingInvitation struct {
+	ID        string
+	Email     string
+	InvitedAt *time.Time `json:",omitempty"`
+	AgeDays   int
+}
+
+func (tfc *TFCClient) membersPending(ctx *cli.Context) error {
+	invitations, err := tfc.listInvitations(ctx.Context)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -ctx.Int("older-than"))
+
+	response := []pendingInvitation{}
+	for _, inv := range invitations {
+		if inv.InvitedAt == nil {
+			// Without a creation time the age is unknown, so report it rather than hide it
+			response = append(response, inv)
+			continue
+		}
+
+		if inv.InvitedAt.Before(cutoff) {
+			inv.AgeDays = int(time.Since(*inv.InvitedAt).Hours() / 24)
+			response = append(response, inv)
+		}
+	}
+
+	sort.Slice(response, func(i, j int) bool { return response[i].AgeDays > response[j].AgeDays })
+
+	r, err := json.MarshalIndent(response, "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+	return nil
+}
+
+// listInvitations lists the invited memberships from the raw API response, because
+// tfe.OrganizationMembership doesn't expose when the invitation was created.
+func (tfc *TFCClient) listInvitations(ctx context.Context) ([]pendingInvitation, error) {
+	opts := &tfe.OrganizationMembershipListOptions{
+		ListOptions: tfe.ListOptions{PageSize: 100},
+		Status:      tfe.OrganizationMembershipInvited,
+	}
+
+	var all []pendingInvitation
+	for {
+		req, err := tfc.Client.NewRequest("GET", fmt.Sprintf("organizations/%s/organization-memberships", url.QueryEscape(tfc.Cfg.OrgName)), opts)
+		if err != nil {
+			return nil, err
+		}
+
+		var buf bytes.Buffer
+		if err := req.Do(ctx, &buf); err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Data []struct {
+				ID         string
+				Attributes struct {
+					Email     string     `json:"email"`
+					CreatedAt *time.Time `json:"created-at"`
+				}
+			}
+			Meta struct {
+				Pagination struct {
+					CurrentPage int `json:"current-page"`
+					NextPage    int `json:"next-page"`
+					TotalPages  int `json:"total-pages"`
+				}
+			}
+		}
+		if err := json.Unmarshal(buf.Bytes(), &page); err != nil {
+			return nil, fmt.Errorf("decoding organization memberships: %w", err)
+		}
+
+		for _, d := range page.Data {
+			all = append(all, pendingInvitation{ID: d.ID, Email: d.Attributes.Email, InvitedAt: d.Attributes.CreatedAt})
+		}
+
+		p := page.Meta.Pagination
+		if p.CurrentPage >= p.TotalPages {
+			return all, nil
+		}
+		opts.PageNumber = p.NextPage
+	}
+}
+
+// resolveMembership reads a membership by ID when given one ("ou-" and 16 characters), otherwise
+// by its email. The user and teams are included.
+func (tfc *TFCClient) resolveMembership(ctx context.Context, emailOrID string) (*tfe.OrganizationMembership, error) {
+	if emailOrID == "" {
+		return nil, fmt.Errorf("an email or organization membership id is required")
+	}
+
+	if looksLikeID("ou-", emailOrID) {
+		m, err := tfc.Client.OrganizationMemberships.ReadWithOptions(ctx, emailOrID, tfe.OrganizationMembershipReadOptions{
+			Include: membershipIncludes,
+		})
+		if err == nil {
+			return m, nil
+		}
+		if !errors.Is(err, tfe.ErrResourceNotFound) {
+			return nil, fmt.Errorf("reading organization membership %s: %w", emailOrID, err)
+		}
+	}
+
+	memberships, err := tfc.listMemberships(ctx, &tfe.OrganizationMembershipListOptions{
+		Include: membershipIncludes,
+		Emails:  []string{emailOrID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range memberships {
+		if strings.EqualFold(m.Email, emailOrID) {
+			return m, nil
+		}
+	}
+
+	return nil, fmt.Errorf("member not found: %s", emailOrID)
+}
+
+// listMemberships returns every organization membership matching opts, walking all pages.
+func (tfc *TFCClient) listMemberships(ctx context.Context, opts *tfe.OrganizationMembershipListOptions) ([]*tfe.OrganizationMembership, error) {
+	opts.PageSize = 100
+
+	var all []*tfe.OrganizationMembership
+	for {
+		ml, err := tfc.Client.OrganizationMemberships.List(ctx, tfc.Cfg.OrgName, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, ml.Items...)
+
+		if ml.Pagination == nil || ml.CurrentPage >= ml.TotalPages {
+			return all, nil
+		}
+		opts.PageNumber = ml.NextPage
+	}
+}
+
+func parseMembershipStatus(s string) (tfe.OrganizationMembershipStatus, error) {
+	switch tfe.OrganizationMembershipStatus(s) {
+	case "", tfe.OrganizationMembershipActive, tfe.OrganizationMembershipInvited:
+		return tfe.OrganizationMembershipStatus(s), nil
+	}
+	return "", fmt.Errorf("status not recognized: %s", s)
+}
@@ -0,0 +1,237 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+// The run queue combines two organization methods:
+//
+//	// ReadRunQueue shows the current run queue of an organization.
+//	ReadRunQueue(ctx context.Context, organization string, options ReadRunQueueOptions) (*RunQueue, error)
+//
+//	// ReadCapacity shows the current run capacity of an organization.
+//	ReadCapacity(ctx context.Context, organization string) (*Capacity, error)
+
+func (tfc *TFCClient) OrgQueueCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "queue",
+		Usage:    "Show the organization's queued and running runs, what they are waiting on, and how many concurrency slots are in use.",
+		Category: "organizations",
+		Action:   tfc.orgQueue,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Usage:   "table or json",
+				Value:   "table",
+			},
+			&cli.BoolFlag{
+				Name:    "watch",
+				Aliases: []string{"w"},
+				Usage:   "Refresh the table until interrupted.",
+			},
+			&cli.DurationFlag{
+				Name:  "interval",
+				Usage: "How often --watch refreshes.",
+				Value: 10 * time.Second,
+			},
+			&cli.IntFlag{
+				Name:  "slots",
+				Usage: "The organization's concurrent run limit. The API doesn't expose it, so free slots are only reported when it's passed.",
+			},
+		},
+	}
+}
+
+type queuedRun struct {
+	ID          string
+	Workspace   string
+	WorkspaceID string
+	Status      string
+	Position    int
+	CreatedAt   time.Time
+	Age         string
+	WaitingOn   string `json:",omitempty"`
+	Message     string `json:",omitempty"`
+}
+
+type runQueueSummary struct {
+	Running   int
+	Pending   int
+	Slots     int `json:",omitempty"`
+	FreeSlots int `json:",omitempty"`
+}
+
+type runQueueResponse struct {
+	Organization string
+	ReadAt       time.Time
+	Summary      runQueueSummary
+	Runs         []queuedRun
+}
+
+func (tfc *TFCClient) orgQueue(ctx *cli.Context) error {
+	format := ctx.String("format")
+	if format != "table" && format != "json" {
+		return fmt.Errorf("format not recognized: %s", format)
+	}
+
+	// Runs in the queue only reference their workspace by id
+	workspaces, err := tfc.listWorkspaces(ctx.Context, &tfe.WorkspaceListOptions{})
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]string, len(workspaces))
+	for _, ws := range workspaces {
+		names[ws.ID] = ws.Name
+	}
+
+	show := func(c context.Context) error {
+		q, err := tfc.readRunQueue(c, names, ctx.Int("slots"))
+		if err != nil {
+			return err
+		}
+
+		if format == "json" {
+			r, err := json.MarshalIndent(q, "", "    ")
+			if err != nil {
+				return nil
+			}
+			fmt.Println(string(r))
+			return nil
+		}
+
+		return writeRunQueueTable(q)
+	}
+
+	if !ctx.Bool("watch") {
+		return show(ctx.Context)
+	}
+
+	c, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(ctx.Duration("interval"))
+	defer ticker.Stop()
+
+	// Only clear the screen between refreshes of a table on a terminal, escape codes would corrupt
+	// JSON and anything redirected to a file
+	redraw := format == "table" && isTerminal(os.Stdout)
+
+	for {
+		if redraw {
+			fmt.Print("\033[H\033[2J")
+		}
+		if err := show(c); err != nil && c.Err() == nil {
+			return err
+		}
+
+		select {
+		case <-c.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (tfc *TFCClient) readRunQueue(ctx context.Context, names map[string]string, slots int) (*runQueueResponse, error) {
+	now := time.Now()
+	q := &runQueueResponse{Organization: tfc.Cfg.OrgName, ReadAt: now, Runs: []queuedRun{}}
+
+	opts := tfe.ReadRunQueueOptions{ListOptions: tfe.ListOptions{PageSize: 100}}
+	for {
+		rq, err := tfc.Client.Organizations.ReadRunQueue(ctx, tfc.Cfg.OrgName, opts)
+		if err != nil {
+			return nil, fmt.Errorf("reading the run queue: %w", err)
+		}
+
+		for _, run := range rq.Items {
+			r := queuedRun{
+				ID:        run.ID,
+				Status:    string(run.Status),
+				Position:  run.PositionInQueue,
+				CreatedAt: run.CreatedAt,
+				Age:       now.Sub(run.CreatedAt).Round(time.Second).String(),
+				WaitingOn: runWaitingOn(run),
+				Message:   run.Message,
+			}
+
+			if run.Workspace != nil {
+				r.WorkspaceID = run.Workspace.ID
+				r.Workspace = names[run.Workspace.ID]
+			}
+
+			q.Runs = append(q.Runs, r)
+		}
+
+		if rq.Pagination == nil || rq.CurrentPage >= rq.TotalPages {
+			break
+		}
+		opts.PageNumber = rq.NextPage
+	}
+
+	sort.SliceStable(q.Runs, func(i, j int) bool { return q.Runs[i].CreatedAt.Before(q.Runs[j].CreatedAt) })
+
+	capacity, err := tfc.Client.Organizations.ReadCapacity(ctx, tfc.Cfg.OrgName)
+	if err != nil {
+		return nil, fmt.Errorf("reading capacity: %w", err)
+	}
+
+	q.Summary = runQueueSummary{Running: capacity.Running, Pending: capacity.Pending}
+	if slots > 0 {
+		q.Summary.Slots = slots
+		if free := slots - capacity.Running; free > 0 {
+			q.Summary.FreeSlots = free
+		}
+	}
+
+	return q, nil
+}
+
+// runWaitingOn describes what a run needs from a person before it can continue.
+func runWaitingOn(run *tfe.Run) string {
+	switch run.Status {
+	case tfe.RunPolicyOverride, tfe.RunPolicySoftFailed:
+		return "policy override"
+	case tfe.RunPostPlanAwaitingDecision:
+		return "run task decision"
+	case tfe.RunPlanned, tfe.RunCostEstimated, tfe.RunPolicyChecked:
+		if run.Actions != nil && run.Actions.IsConfirmable {
+			return "confirmation"
+		}
+	}
+	return ""
+}
+
+func writeRunQueueTable(q *runQueueResponse) error {
+	s := q.Summary
+	fmt.Printf("%s run queue at %s\n", q.Organization, q.ReadAt.Format(time.Kitchen))
+	if s.Slots > 0 {
+		fmt.Printf("%d of %d slots in use, %d free, %d pending\n\n", s.Running, s.Slots, s.FreeSlots, s.Pending)
+	} else {
+		fmt.Printf("%d running, %d pending\n\n", s.Running, s.Pending)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "POSITION\tWORKSPACE\tRUN\tSTATUS\tAGE\tWAITING ON")
+	for _, r := range q.Runs {
+		ws := r.Workspace
+		if ws == "" {
+			ws = r.WorkspaceID
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", r.Position, ws, r.ID, r.Status, r.Age, r.WaitingOn)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+// Organizations describes all the organization related methods that the
+// Terraform Enterprise API supports.
+//
+// TFE API docs: https://www.terraform.io/docs/cloud/api/organizations.html
+//
+//	// Read an organization by its name.
+//	Read(ctx context.Context, organization string) (*Organization, error)
+//
+//	// ReadCapacity shows the current run capacity of an organization.
+//	ReadCapacity(ctx context.Context, organization string) (*Capacity, error)
+//
+//	// ReadEntitlements shows the entitlements of an organization.
+//	ReadEntitlements(ctx context.Context, organization string) (*Entitlements, error)
+
+func (tfc *TFCClient) OrgShowCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "show",
+		Usage:    "Show the organization's settings, enabled features, run capacity and what the current token is allowed to do.",
+		Category: "organizations",
+		Action:   tfc.orgShow,
+	}
+}
+
+type orgShowResponse struct {
+	Name                   string
+	Email                  string
+	CreatedAt              string
+	CollaboratorAuthPolicy string
+	SAMLEnabled            bool
+	CostEstimationEnabled  bool
+	AssessmentsEnforced    bool
+	Features               []string
+	MissingFeatures        []string
+	Capacity               *tfe.Capacity `json:",omitempty"`
+	Permissions            *tfe.OrganizationPermissions
+	Notes                  []string `json:",omitempty"`
+}
+
+func (tfc *TFCClient) orgShow(ctx *cli.Context) error {
+	org, err := tfc.Client.Organizations.Read(ctx.Context, tfc.Cfg.OrgName)
+	if err != nil {
+		switch {
+		case errors.Is(err, tfe.ErrUnauthorized):
+			return fmt.Errorf("the token was rejected, it is invalid, expired or revoked: %w", err)
+		case errors.Is(err, tfe.ErrResourceNotFound):
+			return fmt.Errorf("organization %s doesn't exist or isn't visible to this token: %w", tfc.Cfg.OrgName, err)
+		}
+		return err
+	}
+
+	response := orgShowResponse{
+		Name:                   org.Name,
+		Email:                  org.Email,
+		CreatedAt:              org.CreatedAt.Format("2006-01-02"),
+		CollaboratorAuthPolicy: string(org.CollaboratorAuthPolicy),
+		SAMLEnabled:            org.SAMLEnabled,
+		CostEstimationEnabled:  org.CostEstimationEnabled,
+		AssessmentsEnforced:    org.AssessmentsEnforced,
+		Features:               []string{},
+		MissingFeatures:        []string{},
+		Permissions:            org.Permissions,
+	}
+
+	// Entitlements and capacity need more than read access, report what's missing instead of failing
+	entitlements, err := tfc.Client.Organizations.ReadEntitlements(ctx.Context, tfc.Cfg.OrgName)
+	if err != nil {
+		response.Notes = append(response.Notes, fmt.Sprintf("reading entitlements: %s", err))
+	} else {
+		for name, enabled := range entitlementFeatures(entitlements) {
+			if enabled {
+				response.Features = append(response.Features, name)
+			} else {
+				response.MissingFeatures = append(response.MissingFeatures, name)
+			}
+		}
+		sort.Strings(response.Features)
+		sort.Strings(response.MissingFeatures)
+	}
+
+	capacity, err := tfc.Client.Organizations.ReadCapacity(ctx.Context, tfc.Cfg.OrgName)
+	if err != nil {
+		response.Notes = append(response.Notes, fmt.Sprintf("reading capacity: %s", err))
+	} else {
+		response.Capacity = capacity
+	}
+
+	r, err := json.MarshalIndent(response, "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+	return nil
+}
+
+func entitlementFeatures(e *tfe.Entitlements) map[string]bool {
+	return map[string]bool{
+		"agents":                  e.Agents,
+		"audit-logging":           e.AuditLogging,
+		"cost-estimation":         e.CostEstimation,
+		"operations":              e.Operations,
+		"private-module-registry": e.PrivateModuleRegistry,
+		"run-tasks":               e.RunTasks,
+		"sso":                     e.SSO,
+		"sentinel":                e.Sentinel,
+		"state-storage":           e.StateStorage,
+		"teams":                   e.Teams,
+		"vcs-integrations":        e.VCSIntegrations,
+	}
+}
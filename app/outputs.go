@@ -0,0 +1,335 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/urfave/cli/v2"
+)
+
+func outputsFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:     "workspace",
+			Aliases:  []string{"ws"},
+			Usage:    "(Required) name or id of a workspace to read outputs from. May be repeated.",
+			Required: true,
+		},
+		&cli.StringSliceFlag{
+			Name:  "prefix",
+			Usage: "workspace=PREFIX to prefix the outputs of one workspace, or PREFIX for every workspace. May be repeated.",
+		},
+		&cli.StringFlag{
+			Name:  "separator",
+			Usage: "Joins prefixes, output names and nested keys when flattening.",
+			Value: "_",
+		},
+		&cli.BoolFlag{
+			Name:  "uppercase",
+			Usage: "Uppercase the flattened names.",
+		},
+	}
+}
+
+func (tfc *TFCClient) OutputsExportCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "export",
+		Usage:    "Print workspace outputs as env vars, a dotenv file, tfvars or JSON.",
+		Category: "outputs",
+		Action:   tfc.outputsExport,
+		Flags: append(outputsFlags(),
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Usage:   "env, dotenv, tfvars or json. env and dotenv flatten nested values into one variable per leaf.",
+				Value:   "env",
+			},
+			&cli.BoolFlag{
+				Name:  "include-sensitive",
+				Usage: "Include sensitive outputs. They are skipped by default so they don't end up in files; prefer outputs exec.",
+			},
+		),
+	}
+}
+
+func (tfc *TFCClient) OutputsExecCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "exec",
+		Usage:     "Run a command with workspace outputs, including sensitive ones, in its environment.",
+		UsageText: "tfc-client outputs exec --workspace a [options] -- <command> [args...]",
+		Category:  "outputs",
+		Action:    tfc.outputsExec,
+		Flags:     outputsFlags(),
+	}
+}
+
+// workspaceOutput is an output value named with its workspace prefix applied.
+type workspaceOutput struct {
+	Name      string
+	Value     interface{}
+	Sensitive bool
+}
+
+func (tfc *TFCClient) outputsExport(ctx *cli.Context) error {
+	format := ctx.String("format")
+	switch format {
+	case "env", "dotenv", "tfvars", "json":
+	default:
+		return fmt.Errorf("format not recognized: %s", format)
+	}
+
+	outputs, err := tfc.readWorkspaceOutputs(ctx, ctx.Bool("include-sensitive"))
+	if err != nil {
+		return err
+	}
+
+	if !ctx.Bool("include-sensitive") {
+		kept := outputs[:0]
+		for _, o := range outputs {
+			if o.Sensitive {
+				logf(ctx, "skipping sensitive output %s, pass --include-sensitive to export it", o.Name)
+				continue
+			}
+			kept = append(kept, o)
+		}
+		outputs = kept
+	}
+
+	switch format {
+	case "json":
+		m := make(map[string]interface{}, len(outputs))
+		for _, o := range outputs {
+			m[o.Name] = o.Value
+		}
+
+		r, err := json.MarshalIndent(m, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(r))
+	case "tfvars":
+		for _, o := range outputs {
+			v, err := hclLiteral(o.Value)
+			if err != nil {
+				return fmt.Errorf("output %s: %w", o.Name, err)
+			}
+			fmt.Printf("%s = %s\n", o.Name, v)
+		}
+	default:
+		env, err := flattenOutputs(outputs, ctx.String("separator"), ctx.Bool("uppercase"))
+		if err != nil {
+			return err
+		}
+
+		for _, kv := range env {
+			k, v, _ := strings.Cut(kv, "=")
+			if format == "env" {
+				fmt.Printf("export %s=%s\n", k, shellQuote(v))
+			} else {
+				fmt.Printf("%s=%s\n", k, strconv.Quote(v))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (tfc *TFCClient) outputsExec(ctx *cli.Context) error {
+	if ctx.NArg() == 0 {
+		return fmt.Errorf("expected a command to run after --")
+	}
+
+	outputs, err := tfc.readWorkspaceOutputs(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	env, err := flattenOutputs(outputs, ctx.String("separator"), ctx.Bool("uppercase"))
+	if err != nil {
+		return err
+	}
+
+	args := ctx.Args().Slice()
+	cmd := exec.CommandContext(ctx.Context, args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	logf(ctx, "running %s with %d output variables", args[0], len(env))
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// Let the child decide when to exit. A Ctrl-C already reaches it through the terminal's process
+	// group, so interrupts are only swallowed here, a second one would force stop terraform.
+	// SIGTERM is sent to this process alone and is passed on.
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		for sig := range sigs {
+			if sig == syscall.SIGTERM {
+				_ = cmd.Process.Signal(sig)
+			}
+		}
+	}()
+
+	err = cmd.Wait()
+	signal.Stop(sigs)
+	close(sigs)
+
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return cli.Exit("", exitErr.ExitCode())
+		}
+		return err
+	}
+
+	return nil
+}
+
+// readWorkspaceOutputs reads the current outputs of every --workspace and names them with their prefix.
+func (tfc *TFCClient) readWorkspaceOutputs(ctx *cli.Context, reveal bool) ([]workspaceOutput, error) {
+	sep := ctx.String("separator")
+
+	prefixes := map[string]string{}
+	for _, p := range ctx.StringSlice("prefix") {
+		if ws, prefix, ok := strings.Cut(p, "="); ok {
+			prefixes[ws] = prefix
+		} else {
+			prefixes[""] = p
+		}
+	}
+
+	var (
+		outputs []workspaceOutput
+		seen    = map[string]string{}
+	)
+
+	for _, ref := range ctx.StringSlice("workspace") {
+		ws, err := tfc.resolveWorkspace(ctx.Context, ref)
+		if err != nil {
+			return nil, err
+		}
+
+		prefix, ok := prefixes[ref]
+		if !ok {
+			if prefix, ok = prefixes[ws.Name]; !ok {
+				prefix = prefixes[""]
+			}
+		}
+
+		svo, err := tfc.currentOutputs(ctx, ws.ID, reveal)
+		if err != nil {
+			return nil, err
+		}
+
+		sort.Slice(svo, func(i, j int) bool { return svo[i].Name < svo[j].Name })
+
+		for _, o := range svo {
+			name := o.Name
+			if prefix != "" {
+				name = strings.TrimSuffix(prefix, sep) + sep + name
+			}
+
+			if other, ok := seen[name]; ok {
+				return nil, fmt.Errorf("output %s is in both %s and %s, use --prefix to tell them apart", name, other, ws.Name)
+			}
+			seen[name] = ws.Name
+
+			outputs = append(outputs, workspaceOutput{Name: name, Value: o.Value, Sensitive: o.Sensitive})
+		}
+	}
+
+	return outputs, nil
+}
+
+var envNameInvalid = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// flattenOutputs turns outputs into KEY=value pairs, one per leaf value. Map keys and list
+// indexes are appended to the output name with sep.
+func flattenOutputs(outputs []workspaceOutput, sep string, upper bool) ([]string, error) {
+	flat := map[string]string{}
+
+	var walk func(name string, v interface{}) error
+	walk = func(name string, v interface{}) error {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			for k, child := range t {
+				if err := walk(name+sep+k, child); err != nil {
+					return err
+				}
+			}
+			return nil
+		case []interface{}:
+			for i, child := range t {
+				if err := walk(name+sep+strconv.Itoa(i), child); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		key := envNameInvalid.ReplaceAllString(name, "_")
+		if upper {
+			key = strings.ToUpper(key)
+		}
+
+		if _, ok := flat[key]; ok {
+			return fmt.Errorf("more than one output flattens to %s", key)
+		}
+
+		switch t := v.(type) {
+		case nil:
+			flat[key] = ""
+		case string:
+			flat[key] = t
+		default:
+			b, err := json.Marshal(t)
+			if err != nil {
+				return err
+			}
+			flat[key] = string(b)
+		}
+		return nil
+	}
+
+	for _, o := range outputs {
+		if err := walk(o.Name, o.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	env := make([]string, 0, len(flat))
+	for k, v := range flat {
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+
+	return env, nil
+}
+
+// hclLiteral renders a value as an HCL expression. JSON is valid HCL syntax once template
+// sequences in strings are escaped.
+func hclLiteral(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	s := strings.ReplaceAll(string(b), "${", "$${")
+	return strings.ReplaceAll(s, "%{", "%%{"), nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
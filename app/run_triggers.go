@@ -0,0 +1,343 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+// RunTriggers describes all the Run Trigger related methods that the Terraform
+// Enterprise API supports.
+//
+// TFE API docs: https://www.terraform.io/docs/cloud/api/run-triggers.html
+//
+//	// List all the run triggers within a workspace.
+//	List(ctx context.Context, workspaceID string, options *RunTriggerListOptions) (*RunTriggerList, error)
+//
+//	// Create a new run trigger with the given options.
+//	Create(ctx context.Context, workspaceID string, options RunTriggerCreateOptions) (*RunTrigger, error)
+//
+//	// Delete a run trigger by its ID.
+//	Delete(ctx context.Context, RunTriggerID string) error
+
+// listRunTriggers returns a workspace's inbound or outbound run triggers, walking all pages.
+func (tfc *TFCClient) listRunTriggers(ctx context.Context, workspaceID string, direction tfe.RunTriggerFilterOp) ([]*tfe.RunTrigger, error) {
+	opts := &tfe.RunTriggerListOptions{
+		ListOptions:    tfe.ListOptions{PageSize: 100},
+		RunTriggerType: direction,
+	}
+
+	var all []*tfe.RunTrigger
+	for {
+		rtl, err := tfc.Client.RunTriggers.List(ctx, workspaceID, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, rtl.Items...)
+
+		if rtl.Pagination == nil || rtl.CurrentPage >= rtl.TotalPages {
+			return all, nil
+		}
+		opts.PageNumber = rtl.NextPage
+	}
+}
+
+func runTriggerWorkspaceFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:     "workspace",
+		Aliases:  []string{"ws"},
+		Usage:    "(Required) name or id of the workspace whose runs are triggered.",
+		Required: true,
+	}
+}
+
+func runTriggerSourceFlag() cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:    "source",
+		Aliases: []string{"s"},
+		Usage:   "name or id of a source workspace, whose applies trigger runs in --workspace. May be repeated.",
+	}
+}
+
+func (tfc *TFCClient) RunTriggersListCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "list",
+		Aliases:  []string{"ls"},
+		Usage:    "List the workspaces that trigger runs in a workspace, and the workspaces it triggers runs in.",
+		Category: "run-triggers",
+		Action:   tfc.runTriggersList,
+		Flags:    []cli.Flag{runTriggerWorkspaceFlag()},
+	}
+}
+
+type runTriggerResponse struct {
+	ID          string
+	Workspace   string
+	WorkspaceID string
+	CreatedAt   time.Time
+}
+
+type runTriggersResponse struct {
+	Workspace   string
+	WorkspaceID string
+	// Inbound lists the source workspaces that trigger runs in this one
+	Inbound []runTriggerResponse
+	// Outbound lists the workspaces this one triggers runs in
+	Outbound []runTriggerResponse
+}
+
+func (tfc *TFCClient) runTriggersList(ctx *cli.Context) error {
+	ws, err := tfc.resolveWorkspace(ctx.Context, ctx.String("workspace"))
+	if err != nil {
+		return err
+	}
+
+	inbound, err := tfc.listRunTriggers(ctx.Context, ws.ID, tfe.RunTriggerInbound)
+	if err != nil {
+		return err
+	}
+
+	outbound, err := tfc.listRunTriggers(ctx.Context, ws.ID, tfe.RunTriggerOutbound)
+	if err != nil {
+		return err
+	}
+
+	response := runTriggersResponse{
+		Workspace:   ws.Name,
+		WorkspaceID: ws.ID,
+		Inbound:     []runTriggerResponse{},
+		Outbound:    []runTriggerResponse{},
+	}
+
+	for _, t := range inbound {
+		r := runTriggerResponse{ID: t.ID, Workspace: t.SourceableName, CreatedAt: t.CreatedAt}
+		if t.Sourceable != nil {
+			r.WorkspaceID = t.Sourceable.ID
+		}
+		response.Inbound = append(response.Inbound, r)
+	}
+
+	for _, t := range outbound {
+		r := runTriggerResponse{ID: t.ID, Workspace: t.WorkspaceName, CreatedAt: t.CreatedAt}
+		if t.Workspace != nil {
+			r.WorkspaceID = t.Workspace.ID
+		}
+		response.Outbound = append(response.Outbound, r)
+	}
+
+	sort.Slice(response.Inbound, func(i, j int) bool { return response.Inbound[i].Workspace < response.Inbound[j].Workspace })
+	sort.Slice(response.Outbound, func(i, j int) bool { return response.Outbound[i].Workspace < response.Outbound[j].Workspace })
+
+	r, err := json.MarshalIndent(response, "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+	return nil
+}
+
+func (tfc *TFCClient) RunTriggersCreateCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "create",
+		Usage:    "Trigger runs in a workspace when the source workspaces apply. Existing triggers are left alone.",
+		Category: "run-triggers",
+		Action:   tfc.runTriggersCreate,
+		Flags:    []cli.Flag{runTriggerWorkspaceFlag(), runTriggerSourceFlag()},
+	}
+}
+
+func (tfc *TFCClient) runTriggersCreate(ctx *cli.Context) error {
+	if len(ctx.StringSlice("source")) == 0 {
+		return fmt.Errorf("--source is required")
+	}
+
+	ws, inbound, err := tfc.inboundRunTriggers(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range ctx.StringSlice("source") {
+		source, err := tfc.resolveWorkspace(ctx.Context, s)
+		if err != nil {
+			return err
+		}
+
+		if t, ok := inbound[source.ID]; ok {
+			fmt.Printf("unchanged %s triggers %s (%s)\n", source.Name, ws.Name, t.ID)
+			continue
+		}
+
+		t, err := tfc.Client.RunTriggers.Create(ctx.Context, ws.ID, tfe.RunTriggerCreateOptions{Sourceable: source})
+		if err != nil {
+			return fmt.Errorf("creating a run trigger from %s: %w", source.Name, err)
+		}
+
+		fmt.Printf("created %s triggers %s (%s)\n", source.Name, ws.Name, t.ID)
+	}
+
+	return nil
+}
+
+func (tfc *TFCClient) RunTriggersDeleteCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "delete",
+		Aliases:  []string{"rm"},
+		Usage:    "Stop the source workspaces from triggering runs in a workspace.",
+		Category: "run-triggers",
+		Action:   tfc.runTriggersDelete,
+		Flags:    []cli.Flag{runTriggerWorkspaceFlag(), runTriggerSourceFlag()},
+	}
+}
+
+func (tfc *TFCClient) runTriggersDelete(ctx *cli.Context) error {
+	if len(ctx.StringSlice("source")) == 0 {
+		return fmt.Errorf("--source is required")
+	}
+
+	ws, inbound, err := tfc.inboundRunTriggers(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range ctx.StringSlice("source") {
+		source, err := tfc.resolveWorkspace(ctx.Context, s)
+		if err != nil {
+			return err
+		}
+
+		t, ok := inbound[source.ID]
+		if !ok {
+			return fmt.Errorf("%s doesn't trigger runs in %s", source.Name, ws.Name)
+		}
+
+		if err := tfc.Client.RunTriggers.Delete(ctx.Context, t.ID); err != nil {
+			return fmt.Errorf("deleting run trigger %s: %w", t.ID, err)
+		}
+
+		fmt.Printf("deleted %s triggers %s (%s)\n", source.Name, ws.Name, t.ID)
+	}
+
+	return nil
+}
+
+func (tfc *TFCClient) RunTriggersSyncCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "sync",
+		Usage:    "Make a workspace's source workspaces exactly the ones in a file, adding missing run triggers and deleting extra ones.",
+		Category: "run-triggers",
+		Action:   tfc.runTriggersSync,
+		Flags: []cli.Flag{
+			runTriggerWorkspaceFlag(),
+			&cli.StringFlag{
+				Name:     "file",
+				Aliases:  []string{"f"},
+				Usage:    "(Required) File with one source workspace name or id per line, or \"-\" for stdin. Blank lines and lines starting with # are ignored.",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Print the changes without making them.",
+			},
+		},
+	}
+}
+
+func (tfc *TFCClient) runTriggersSync(ctx *cli.Context) error {
+	lines, err := readLines(ctx.String("file"))
+	if err != nil {
+		return err
+	}
+
+	ws, inbound, err := tfc.inboundRunTriggers(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Resolve every source before changing anything, so a typo doesn't leave the workspace half synced
+	want := map[string]*tfe.Workspace{}
+	for _, l := range lines {
+		source, err := tfc.resolveWorkspace(ctx.Context, l)
+		if err != nil {
+			return err
+		}
+		want[source.ID] = source
+	}
+
+	var add []*tfe.Workspace
+	for id, source := range want {
+		if _, ok := inbound[id]; !ok {
+			add = append(add, source)
+		}
+	}
+	sort.Slice(add, func(i, j int) bool { return add[i].Name < add[j].Name })
+
+	var remove []*tfe.RunTrigger
+	for id, t := range inbound {
+		if _, ok := want[id]; !ok {
+			remove = append(remove, t)
+		}
+	}
+	sort.Slice(remove, func(i, j int) bool { return remove[i].SourceableName < remove[j].SourceableName })
+
+	for _, source := range add {
+		fmt.Printf("+ %s\n", source.Name)
+	}
+	for _, t := range remove {
+		fmt.Printf("- %s\n", t.SourceableName)
+	}
+	fmt.Printf("%s: %d to add, %d to delete, %d unchanged\n", ws.Name, len(add), len(remove), len(want)-len(add))
+
+	if ctx.Bool("dry-run") {
+		return nil
+	}
+
+	var failed []string
+	for _, source := range add {
+		if _, err := tfc.Client.RunTriggers.Create(ctx.Context, ws.ID, tfe.RunTriggerCreateOptions{Sourceable: source}); err != nil {
+			logf(ctx, "creating a run trigger from %s: %s", source.Name, err)
+			failed = append(failed, source.Name)
+		}
+	}
+
+	for _, t := range remove {
+		if err := tfc.Client.RunTriggers.Delete(ctx.Context, t.ID); err != nil {
+			logf(ctx, "deleting run trigger %s from %s: %s", t.ID, t.SourceableName, err)
+			failed = append(failed, t.SourceableName)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to sync the run triggers from %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// inboundRunTriggers resolves --workspace and returns its inbound run triggers by source workspace id.
+func (tfc *TFCClient) inboundRunTriggers(ctx *cli.Context) (*tfe.Workspace, map[string]*tfe.RunTrigger, error) {
+	ws, err := tfc.resolveWorkspace(ctx.Context, ctx.String("workspace"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	triggers, err := tfc.listRunTriggers(ctx.Context, ws.ID, tfe.RunTriggerInbound)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inbound := make(map[string]*tfe.RunTrigger, len(triggers))
+	for _, t := range triggers {
+		if t.Sourceable != nil {
+			inbound[t.Sourceable.ID] = t
+		}
+	}
+
+	return ws, inbound, nil
+}
@@ -0,0 +1,313 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+// apply-if reads the run's JSON plan and only confirms it when the guard rules pass:
+//
+//	// Plans.ReadJSONOutput retrieves the JSON execution plan.
+//	ReadJSONOutput(ctx context.Context, planID string) ([]byte, error)
+//
+//	// Runs.Apply a run by its ID.
+//	Apply(ctx context.Context, runID string, options RunApplyOptions) error
+//
+//	// Runs.Discard a run by its ID.
+//	Discard(ctx context.Context, runID string, options RunDiscardOptions) error
+
+func (tfc *TFCClient) RunsApplyIfCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "apply-if",
+		Usage:    "Apply a run only if its plan passes the guard rules, otherwise leave or discard it and exit non-zero.",
+		Category: "runs",
+		Action:   tfc.runsApplyIf,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "run",
+				Aliases:  []string{"r"},
+				Usage:    "(Required) id of the run",
+				Required: true,
+			},
+			&cli.IntFlag{
+				Name:  "max-destroy",
+				Usage: "The most resources the plan may delete, replacements included. Negative means no limit.",
+				Value: -1,
+			},
+			&cli.StringSliceFlag{
+				Name:  "deny-type",
+				Usage: "Fail if the plan creates, updates or deletes a resource of this type. * is a wildcard. May be repeated.",
+			},
+			&cli.StringSliceFlag{
+				Name:  "deny-replace",
+				Usage: "Fail if the plan replaces a resource whose address matches, e.g. 'module.vpc.*'. May be repeated.",
+			},
+			&cli.StringSliceFlag{
+				Name:  "deny-delete",
+				Usage: "Fail if the plan deletes a resource whose address matches, replacements included. May be repeated.",
+			},
+			&cli.StringFlag{
+				Name:  "rules",
+				Usage: "JSON file of rules, or \"-\" for stdin, added to the flags: {\"max-destroy\": 0, \"deny-type\": [], \"deny-replace\": [], \"deny-delete\": []}",
+			},
+			&cli.StringFlag{
+				Name:  "on-fail",
+				Usage: "leave or discard the run when a rule fails",
+				Value: "leave",
+			},
+			&cli.StringFlag{
+				Name:    "comment",
+				Aliases: []string{"c"},
+				Usage:   "Comment added when the run is applied or discarded.",
+			},
+			&cli.DurationFlag{
+				Name:  "wait",
+				Usage: "How long to wait for the plan to finish before giving up.",
+				Value: 30 * time.Minute,
+			},
+		},
+	}
+}
+
+type applyGuard struct {
+	MaxDestroy  *int     `json:"max-destroy,omitempty"`
+	DenyType    []string `json:"deny-type,omitempty"`
+	DenyReplace []string `json:"deny-replace,omitempty"`
+	DenyDelete  []string `json:"deny-delete,omitempty"`
+}
+
+// jsonPlan is the part of `terraform show -json` output the guard rules look at.
+type jsonPlan struct {
+	ResourceChanges []struct {
+		Address string `json:"address"`
+		Type    string `json:"type"`
+		Mode    string `json:"mode"`
+		Change  struct {
+			Actions []string `json:"actions"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+}
+
+type applyIfResult struct {
+	Run        string
+	Status     string
+	Creates    int
+	Updates    int
+	Deletes    int
+	Replaces   int
+	Violations []string `json:",omitempty"`
+	Action     string
+}
+
+func (tfc *TFCClient) runsApplyIf(ctx *cli.Context) error {
+	onFail := ctx.String("on-fail")
+	if onFail != "leave" && onFail != "discard" {
+		return fmt.Errorf("--on-fail must be leave or discard, got %s", onFail)
+	}
+
+	guard, err := loadApplyGuard(ctx)
+	if err != nil {
+		return err
+	}
+
+	run, err := tfc.waitForPlan(ctx, ctx.String("run"), ctx.Duration("wait"))
+	if err != nil {
+		return err
+	}
+
+	// A plan without changes finishes on its own, there is nothing to guard
+	if run.Status == tfe.RunPlannedAndFinished {
+		r, err := json.MarshalIndent(applyIfResult{Run: run.ID, Status: string(run.Status), Action: "nothing to apply"}, "", "    ")
+		if err != nil {
+			return nil
+		}
+		fmt.Println(string(r))
+		return nil
+	}
+
+	if run.Actions == nil || !run.Actions.IsConfirmable {
+		return cli.Exit(fmt.Sprintf("run %s can't be applied, its status is %s", run.ID, run.Status), 1)
+	}
+
+	if run.Plan == nil {
+		return fmt.Errorf("run %s has no plan", run.ID)
+	}
+
+	b, err := tfc.Client.Plans.ReadJSONOutput(ctx.Context, run.Plan.ID)
+	if err != nil {
+		return fmt.Errorf("reading the JSON plan of %s: %w", run.ID, err)
+	}
+
+	var plan jsonPlan
+	if err := json.Unmarshal(b, &plan); err != nil {
+		return fmt.Errorf("decoding the JSON plan of %s: %w", run.ID, err)
+	}
+
+	result := evaluateApplyGuard(guard, &plan)
+	result.Run, result.Status = run.ID, string(run.Status)
+	comment := ptrString(ctx.String("comment"))
+
+	switch {
+	case len(result.Violations) == 0:
+		if err := tfc.Client.Runs.Apply(ctx.Context, run.ID, tfe.RunApplyOptions{Comment: comment}); err != nil {
+			return fmt.Errorf("applying %s: %w", run.ID, err)
+		}
+		result.Action = "applied"
+	case onFail == "discard":
+		if comment == nil {
+			comment = ptrString("discarded by apply-if: " + strings.Join(result.Violations, "; "))
+		}
+		if err := tfc.Client.Runs.Discard(ctx.Context, run.ID, tfe.RunDiscardOptions{Comment: comment}); err != nil {
+			return fmt.Errorf("discarding %s: %w", run.ID, err)
+		}
+		result.Action = "discarded"
+	default:
+		result.Action = "left for review"
+	}
+
+	r, err := json.MarshalIndent(result, "", "    ")
+	if err != nil {
+		return nil
+	}
+	fmt.Println(string(r))
+
+	if len(result.Violations) > 0 {
+		return cli.Exit(fmt.Sprintf("run %s %s, %d guard rules failed:\n  %s", run.ID, result.Action, len(result.Violations), strings.Join(result.Violations, "\n  ")), 1)
+	}
+	return nil
+}
+
+func loadApplyGuard(ctx *cli.Context) (*applyGuard, error) {
+	g := &applyGuard{
+		DenyType:    ctx.StringSlice("deny-type"),
+		DenyReplace: ctx.StringSlice("deny-replace"),
+		DenyDelete:  ctx.StringSlice("deny-delete"),
+	}
+
+	if ctx.Int("max-destroy") >= 0 {
+		n := ctx.Int("max-destroy")
+		g.MaxDestroy = &n
+	}
+
+	if ctx.IsSet("rules") {
+		b, err := readInput(ctx.String("rules"))
+		if err != nil {
+			return nil, err
+		}
+
+		var file applyGuard
+		if err := json.Unmarshal(b, &file); err != nil {
+			return nil, fmt.Errorf("invalid rules file: %w", err)
+		}
+
+		// The stricter limit wins
+		if file.MaxDestroy != nil && (g.MaxDestroy == nil || *file.MaxDestroy < *g.MaxDestroy) {
+			g.MaxDestroy = file.MaxDestroy
+		}
+		g.DenyType = append(g.DenyType, file.DenyType...)
+		g.DenyReplace = append(g.DenyReplace, file.DenyReplace...)
+		g.DenyDelete = append(g.DenyDelete, file.DenyDelete...)
+	}
+
+	return g, nil
+}
+
+// evaluateApplyGuard counts the plan's managed resource changes and lists every rule they break.
+func evaluateApplyGuard(g *applyGuard, plan *jsonPlan) applyIfResult {
+	var r applyIfResult
+
+	matchesAny := func(patterns []string, s string) string {
+		for _, p := range patterns {
+			if globMatch(p, s) {
+				return p
+			}
+		}
+		return ""
+	}
+
+	for _, rc := range plan.ResourceChanges {
+		if rc.Mode == "data" {
+			continue
+		}
+
+		actions := strings.Join(rc.Change.Actions, ",")
+		var deletes bool
+		switch actions {
+		case "no-op", "read", "":
+			continue
+		case "create":
+			r.Creates++
+		case "update":
+			r.Updates++
+		case "delete":
+			r.Deletes++
+			deletes = true
+		case "delete,create", "create,delete":
+			r.Replaces++
+			deletes = true
+			if p := matchesAny(g.DenyReplace, rc.Address); p != "" {
+				r.Violations = append(r.Violations, fmt.Sprintf("%s is replaced, denied by --deny-replace %s", rc.Address, p))
+			}
+		}
+
+		if p := matchesAny(g.DenyType, rc.Type); p != "" {
+			r.Violations = append(r.Violations, fmt.Sprintf("%s is changed (%s), denied by --deny-type %s", rc.Address, actions, p))
+		}
+
+		if deletes {
+			if p := matchesAny(g.DenyDelete, rc.Address); p != "" {
+				r.Violations = append(r.Violations, fmt.Sprintf("%s is deleted, denied by --deny-delete %s", rc.Address, p))
+			}
+		}
+	}
+
+	if g.MaxDestroy != nil && r.Deletes+r.Replaces > *g.MaxDestroy {
+		r.Violations = append(r.Violations, fmt.Sprintf("the plan deletes %d resources (%d replaced), more than --max-destroy %d", r.Deletes+r.Replaces, r.Replaces, *g.MaxDestroy))
+	}
+
+	return r
+}
+
+// waitForPlan polls the run until its plan and the checks that follow it have finished.
+func (tfc *TFCClient) waitForPlan(ctx *cli.Context, runID string, timeout time.Duration) (*tfe.Run, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		run, err := tfc.Client.Runs.Read(ctx.Context, runID)
+		if err != nil {
+			return nil, fmt.Errorf("reading run %s: %w", runID, err)
+		}
+
+		if !runIsPlanning(run.Status) {
+			return run, nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("run %s is still %s after %s", runID, run.Status, timeout)
+		}
+
+		logf(ctx, "run %s is %s, waiting", runID, run.Status)
+
+		select {
+		case <-ctx.Context.Done():
+			return nil, ctx.Context.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// runIsPlanning reports whether the run hasn't reached a decision point yet.
+func runIsPlanning(s tfe.RunStatus) bool {
+	switch s {
+	case tfe.RunPending, tfe.RunFetching, tfe.RunFetchingCompleted, tfe.RunQueuing, tfe.RunPlanQueued,
+		tfe.RunPrePlanRunning, tfe.RunPrePlanCompleted, tfe.RunPlanning, tfe.RunCostEstimating,
+		tfe.RunPolicyChecking, tfe.RunPostPlanRunning, tfe.RunPostPlanCompleted:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,256 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+// Runs awaiting approval are found and acted on with:
+//
+//	// Runs.List all the runs of the given workspace.
+//	List(ctx context.Context, workspaceID string, options *RunListOptions) (*RunList, error)
+//
+//	// Plans.Read a plan by its ID.
+//	Read(ctx context.Context, planID string) (*Plan, error)
+//
+//	// Runs.Apply a run by its ID.
+//	Apply(ctx context.Context, runID string, options RunApplyOptions) error
+//
+//	// Runs.Discard a run by its ID.
+//	Discard(ctx context.Context, runID string, options RunDiscardOptions) error
+
+var awaitingApprovalStatuses = []tfe.RunStatus{tfe.RunPlanned, tfe.RunCostEstimated, tfe.RunPolicyChecked}
+
+func (tfc *TFCClient) RunsPendingApprovalCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "pending-approval",
+		Aliases:  []string{"pending"},
+		Usage:    "Find runs waiting for confirmation and apply, discard or skip each one.",
+		Category: "runs",
+		Action:   tfc.runsPendingApproval,
+		Flags: []cli.Flag{
+			selectorFlag(),
+			&cli.BoolFlag{
+				Name:  "list",
+				Usage: "Print the runs as JSON instead of prompting for each one.",
+			},
+			concurrencyFlag(),
+		},
+	}
+}
+
+type pendingRun struct {
+	ID           string
+	Workspace    string
+	WorkspaceID  string
+	Status       string
+	Message      string `json:",omitempty"`
+	CreatedAt    time.Time
+	IsDestroy    bool
+	Additions    int
+	Changes      int
+	Destructions int
+	Discardable  bool
+}
+
+func (tfc *TFCClient) runsPendingApproval(ctx *cli.Context) error {
+	runs, failed, err := tfc.findPendingRuns(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The runs that were found can still be reviewed, but the command fails once they have been
+	incomplete := func() error {
+		if failed > 0 {
+			return fmt.Errorf("failed to read the runs of %d workspaces, their runs are missing", failed)
+		}
+		return nil
+	}
+
+	if ctx.Bool("list") {
+		r, err := json.MarshalIndent(runs, "", "    ")
+		if err != nil {
+			return nil
+		}
+		fmt.Println(string(r))
+		return incomplete()
+	}
+
+	if len(runs) == 0 {
+		fmt.Println("no runs are waiting for approval")
+		return incomplete()
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	prompt := func(q string) (string, error) {
+		fmt.Print(q)
+		line, err := in.ReadString('\n')
+		if err == io.EOF && line != "" {
+			err = nil
+		}
+		return strings.TrimSpace(line), err
+	}
+
+	var applied, discarded, failedRuns int
+
+loop:
+	for i, r := range runs {
+		fmt.Printf("\n[%d/%d] %s %s (%s)\n", i+1, len(runs), r.Workspace, r.ID, r.Status)
+		if r.Message != "" {
+			fmt.Printf("  message: %s\n", r.Message)
+		}
+		fmt.Printf("  created %s ago, plan: +%d ~%d -%d", time.Since(r.CreatedAt).Round(time.Minute), r.Additions, r.Changes, r.Destructions)
+		if r.IsDestroy {
+			fmt.Print(" (destroy)")
+		}
+		fmt.Println()
+
+		// Ask again until the answer is one we know
+		for {
+			answer, err := prompt("  [a]pply, [d]iscard, [s]kip or [q]uit? ")
+			if err != nil {
+				break loop
+			}
+
+			switch strings.ToLower(answer) {
+			case "a", "apply":
+				comment, err := prompt("  comment: ")
+				if err != nil {
+					return err
+				}
+				// The run may have been applied or discarded by someone else since it was listed
+				if err := tfc.Client.Runs.Apply(ctx.Context, r.ID, tfe.RunApplyOptions{Comment: ptrString(comment)}); err != nil {
+					fmt.Printf("  applying %s failed: %s\n", r.ID, err)
+					failedRuns++
+					continue loop
+				}
+				fmt.Printf("  applied %s\n", r.ID)
+				applied++
+			case "d", "discard":
+				if !r.Discardable {
+					fmt.Println("  the run can't be discarded")
+					continue
+				}
+				comment, err := prompt("  comment: ")
+				if err != nil {
+					return err
+				}
+				if err := tfc.Client.Runs.Discard(ctx.Context, r.ID, tfe.RunDiscardOptions{Comment: ptrString(comment)}); err != nil {
+					fmt.Printf("  discarding %s failed: %s\n", r.ID, err)
+					failedRuns++
+					continue loop
+				}
+				fmt.Printf("  discarded %s\n", r.ID)
+				discarded++
+			case "s", "skip":
+			case "q", "quit":
+				break loop
+			default:
+				fmt.Printf("  answer not recognized: %s\n", answer)
+				continue
+			}
+			continue loop
+		}
+	}
+
+	fmt.Printf("\n%d applied, %d discarded, %d failed, %d skipped\n", applied, discarded, failedRuns, len(runs)-applied-discarded-failedRuns)
+
+	if failedRuns > 0 {
+		return fmt.Errorf("failed to apply or discard %d runs", failedRuns)
+	}
+	return incomplete()
+}
+
+// findPendingRuns lists the confirmable runs of every selected workspace with their plan's resource
+// counts, and the number of workspaces whose runs couldn't be read.
+func (tfc *TFCClient) findPendingRuns(ctx *cli.Context) ([]pendingRun, int, error) {
+	workspaces, err := tfc.selectWorkspaces(ctx.Context, ctx.StringSlice("selector"))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	statuses := make([]string, len(awaitingApprovalStatuses))
+	for i, s := range awaitingApprovalStatuses {
+		statuses[i] = string(s)
+	}
+
+	logf(ctx, "looking for runs awaiting approval in %d workspaces", len(workspaces))
+
+	var (
+		mu     sync.Mutex
+		runs   = []pendingRun{}
+		failed int
+	)
+
+	err = forEachParallel(ctx.Context, ctx.Int("concurrency"), workspaces, func(c context.Context, ws *tfe.Workspace) error {
+		found, err := tfc.pendingRunsIn(c, ws, strings.Join(statuses, ","))
+		mu.Lock()
+		defer mu.Unlock()
+
+		if err != nil {
+			logf(ctx, "%s: %s", ws.Name, err)
+			failed++
+			return nil
+		}
+
+		runs = append(runs, found...)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.Slice(runs, func(i, j int) bool { return runs[i].CreatedAt.Before(runs[j].CreatedAt) })
+	return runs, failed, nil
+}
+
+func (tfc *TFCClient) pendingRunsIn(ctx context.Context, ws *tfe.Workspace, status string) ([]pendingRun, error) {
+	rl, err := tfc.Client.Runs.List(ctx, ws.ID, &tfe.RunListOptions{
+		ListOptions: tfe.ListOptions{PageSize: 100},
+		Status:      status,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var found []pendingRun
+	for _, run := range rl.Items {
+		if run.Actions == nil || !run.Actions.IsConfirmable {
+			continue
+		}
+
+		r := pendingRun{
+			ID:          run.ID,
+			Workspace:   ws.Name,
+			WorkspaceID: ws.ID,
+			Status:      string(run.Status),
+			Message:     run.Message,
+			CreatedAt:   run.CreatedAt,
+			IsDestroy:   run.IsDestroy,
+			Discardable: run.Actions.IsDiscardable,
+		}
+
+		if run.Plan != nil {
+			plan, err := tfc.Client.Plans.Read(ctx, run.Plan.ID)
+			if err != nil {
+				return nil, fmt.Errorf("reading plan %s: %w", run.Plan.ID, err)
+			}
+			r.Additions, r.Changes, r.Destructions = plan.ResourceAdditions, plan.ResourceChanges, plan.ResourceDestructions
+		}
+
+		found = append(found, r)
+	}
+
+	return found, nil
+}
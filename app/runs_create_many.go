@@ -0,0 +1,324 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+// create-many fans a run out over the selected workspaces and follows each one with:
+//
+//	// Runs.Create a new run with the given options.
+//	Create(ctx context.Context, options RunCreateOptions) (*Run, error)
+//
+//	// Runs.Read a run by its ID.
+//	Read(ctx context.Context, runID string) (*Run, error)
+
+func (tfc *TFCClient) RunsCreateManyCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "create-many",
+		Usage:    "Create a run in every workspace matching --selector and follow them to completion.",
+		Category: "runs",
+		Action:   tfc.runsCreateMany,
+		Flags: []cli.Flag{
+			selectorFlag(),
+			&cli.BoolFlag{
+				Name:  "plan-only",
+				Usage: "Create speculative runs that can't be applied.",
+			},
+			&cli.BoolFlag{
+				Name:  "auto-apply",
+				Usage: "Apply the runs without confirmation. Otherwise runs that need confirmation are reported and left waiting.",
+			},
+			&cli.StringFlag{
+				Name:    "message",
+				Aliases: []string{"m"},
+				Usage:   "Message to be associated with the runs.",
+			},
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Usage: "The number of runs in flight at once.",
+				Value: 5,
+			},
+			&cli.DurationFlag{
+				Name:  "poll",
+				Usage: "How often each run is checked.",
+				Value: 10 * time.Second,
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "How long to follow each run before giving up on it.",
+				Value: time.Hour,
+			},
+			&cli.StringFlag{
+				Name:  "report",
+				Usage: "Write a JSON report of every run to this file.",
+			},
+		},
+	}
+}
+
+type fanOutRun struct {
+	Workspace    string
+	WorkspaceID  string
+	RunID        string `json:",omitempty"`
+	Status       string
+	Outcome      string
+	Additions    int
+	Changes      int
+	Destructions int
+	Error        string `json:",omitempty"`
+	StartedAt    time.Time
+	FinishedAt   time.Time
+}
+
+type fanOutSummary struct {
+	Total                int
+	Changes              int
+	NoChanges            int
+	AwaitingConfirmation int
+	Errors               int
+}
+
+type fanOutReport struct {
+	Summary fanOutSummary
+	Runs    []*fanOutRun
+}
+
+// Run outcomes.
+const (
+	outcomeChanges   = "changes"
+	outcomeNoChanges = "no changes"
+	outcomeAwaiting  = "awaiting confirmation"
+	outcomeError     = "error"
+)
+
+func (tfc *TFCClient) runsCreateMany(ctx *cli.Context) error {
+	if !ctx.IsSet("selector") {
+		return fmt.Errorf("--selector is required, runs aren't created in every workspace by default")
+	}
+
+	if ctx.Bool("plan-only") && ctx.Bool("auto-apply") {
+		return fmt.Errorf("--plan-only and --auto-apply can't be combined")
+	}
+
+	workspaces, err := tfc.selectWorkspaces(ctx.Context, ctx.StringSlice("selector"))
+	if err != nil {
+		return err
+	}
+
+	if len(workspaces) == 0 {
+		return fmt.Errorf("no workspaces match the selector")
+	}
+
+	report := &fanOutReport{Runs: make([]*fanOutRun, len(workspaces))}
+	byWorkspace := make(map[string]*fanOutRun, len(workspaces))
+	for i, ws := range workspaces {
+		report.Runs[i] = &fanOutRun{Workspace: ws.Name, WorkspaceID: ws.ID, Status: "waiting"}
+		byWorkspace[ws.ID] = report.Runs[i]
+	}
+	sort.Slice(report.Runs, func(i, j int) bool { return report.Runs[i].Workspace < report.Runs[j].Workspace })
+
+	// Redraw the table in place on a terminal, anywhere else the escape codes would end up in the log
+	var (
+		mu     sync.Mutex
+		redraw = isTerminal(os.Stderr)
+		logged = make(map[*fanOutRun]string, len(report.Runs))
+	)
+	update := func(f func()) {
+		mu.Lock()
+		defer mu.Unlock()
+		f()
+		if redraw {
+			writeFanOutTable(report.Runs)
+		} else {
+			logFanOutChanges(report.Runs, logged)
+		}
+	}
+
+	err = forEachParallel(ctx.Context, ctx.Int("concurrency"), workspaces, func(c context.Context, ws *tfe.Workspace) error {
+		r := byWorkspace[ws.ID]
+		tfc.followNewRun(c, ctx, ws, r, update)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range report.Runs {
+		switch r.Outcome {
+		case outcomeChanges:
+			report.Summary.Changes++
+		case outcomeNoChanges:
+			report.Summary.NoChanges++
+		case outcomeAwaiting:
+			report.Summary.AwaitingConfirmation++
+		default:
+			report.Summary.Errors++
+		}
+	}
+	report.Summary.Total = len(report.Runs)
+
+	if ctx.IsSet("report") {
+		b, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(ctx.String("report"), b); err != nil {
+			return fmt.Errorf("writing the report: %w", err)
+		}
+	}
+
+	s := report.Summary
+	fmt.Printf("%d runs: %d with changes, %d without changes, %d awaiting confirmation, %d errors\n",
+		s.Total, s.Changes, s.NoChanges, s.AwaitingConfirmation, s.Errors)
+
+	if s.Errors > 0 {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
+
+// followNewRun creates a run in the workspace and polls it until it finishes, needs confirmation,
+// or times out. Failures are recorded on r rather than returned so the other runs carry on.
+func (tfc *TFCClient) followNewRun(c context.Context, ctx *cli.Context, ws *tfe.Workspace, r *fanOutRun, update func(func())) {
+	fail := func(err error) {
+		update(func() {
+			r.Outcome, r.Error, r.FinishedAt = outcomeError, err.Error(), time.Now()
+		})
+	}
+
+	autoApply := ctx.Bool("auto-apply")
+	run, err := tfc.Client.Runs.Create(c, tfe.RunCreateOptions{
+		Workspace: ws,
+		Message:   ptrString(ctx.String("message")),
+		PlanOnly:  getIfSetBool(ctx, "plan-only"),
+		AutoApply: getIfSetBool(ctx, "auto-apply"),
+	})
+	if err != nil {
+		fail(fmt.Errorf("creating run: %w", err))
+		return
+	}
+
+	update(func() { r.RunID, r.Status, r.StartedAt = run.ID, string(run.Status), time.Now() })
+
+	deadline := time.Now().Add(ctx.Duration("timeout"))
+	for {
+		select {
+		case <-c.Done():
+			fail(c.Err())
+			return
+		case <-time.After(ctx.Duration("poll")):
+		}
+
+		run, err = tfc.Client.Runs.Read(c, run.ID)
+		if err != nil {
+			fail(fmt.Errorf("reading run: %w", err))
+			return
+		}
+
+		if string(run.Status) != r.Status {
+			update(func() { r.Status = string(run.Status) })
+		}
+
+		outcome := runOutcome(run, autoApply || ws.AutoApply)
+		if outcome != "" {
+			var plan *tfe.Plan
+			if run.Plan != nil {
+				if plan, err = tfc.Client.Plans.Read(c, run.Plan.ID); err != nil {
+					logf(ctx, "%s: reading plan %s: %s", ws.Name, run.Plan.ID, err)
+				}
+			}
+
+			update(func() {
+				r.Outcome, r.FinishedAt = outcome, time.Now()
+				if plan != nil {
+					r.Additions, r.Changes, r.Destructions = plan.ResourceAdditions, plan.ResourceChanges, plan.ResourceDestructions
+				}
+			})
+			return
+		}
+
+		if time.Now().After(deadline) {
+			fail(fmt.Errorf("still %s after %s", run.Status, ctx.Duration("timeout")))
+			return
+		}
+	}
+}
+
+// runOutcome returns the outcome of a run that has stopped moving, or "" while it is still going.
+func runOutcome(run *tfe.Run, autoApply bool) string {
+	switch run.Status {
+	case tfe.RunApplied:
+		return outcomeChanges
+	case tfe.RunPlannedAndFinished:
+		if run.HasChanges {
+			return outcomeChanges
+		}
+		return outcomeNoChanges
+	case tfe.RunErrored, tfe.RunCanceled, tfe.RunDiscarded, "force_canceled":
+		return outcomeError
+	case tfe.RunPolicySoftFailed, tfe.RunPolicyOverride, tfe.RunPostPlanAwaitingDecision:
+		return outcomeAwaiting
+	case tfe.RunPlanned, tfe.RunCostEstimated, tfe.RunPolicyChecked:
+		if !autoApply && run.Actions != nil && run.Actions.IsConfirmable {
+			return outcomeAwaiting
+		}
+	}
+	return ""
+}
+
+// writeFanOutTable redraws the status of every run on stderr, leaving stdout for the summary.
+func writeFanOutTable(runs []*fanOutRun) {
+	fmt.Fprint(os.Stderr, "\033[H\033[2J")
+
+	w := tabwriter.NewWriter(os.Stderr, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "WORKSPACE\tRUN\tSTATUS\tPLAN\tOUTCOME")
+	for _, r := range runs {
+		fmt.Fprintln(w, strings.Join(fanOutRow(r), "\t"))
+	}
+	w.Flush()
+}
+
+// logFanOutChanges writes a line to stderr for every run whose row changed since it was last logged.
+func logFanOutChanges(runs []*fanOutRun, logged map[*fanOutRun]string) {
+	for _, r := range runs {
+		var cols []string
+		for _, c := range fanOutRow(r) {
+			if c != "" {
+				cols = append(cols, c)
+			}
+		}
+
+		line := strings.Join(cols, " ")
+		if logged[r] == line {
+			continue
+		}
+		logged[r] = line
+		fmt.Fprintln(os.Stderr, line)
+	}
+}
+
+// fanOutRow returns the workspace, run, status, plan and outcome columns of a run.
+func fanOutRow(r *fanOutRun) []string {
+	plan := ""
+	if r.Outcome != "" && r.Outcome != outcomeError {
+		plan = fmt.Sprintf("+%d ~%d -%d", r.Additions, r.Changes, r.Destructions)
+	}
+
+	outcome := r.Outcome
+	if r.Error != "" {
+		outcome += ": " + r.Error
+	}
+
+	return []string{r.Workspace, r.RunID, r.Status, plan, outcome}
+}
@@ -0,0 +1,357 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+func (tfc *TFCClient) RunsOrchestrateCmd() *cli.Command {
+	return &cli.Command{
+		Name: "orchestrate",
+		Usage: "Apply a workspace and everything downstream of it through run triggers and remote state consumers, one dependency level at a time. " +
+			"Workspaces in a level run in parallel, and a failure stops every workspace that depends on it.",
+		Category: "runs",
+		Action:   tfc.runsOrchestrate,
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:     "root",
+				Usage:    "(Required) name or id of the workspace to start from. May be repeated.",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:    "message",
+				Aliases: []string{"m"},
+				Usage:   "Message to be associated with the runs.",
+				Value:   "orchestrated by tfc-cli",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Print the levels without creating runs.",
+			},
+			concurrencyFlag(),
+			&cli.DurationFlag{
+				Name:  "poll",
+				Usage: "How often each run is checked.",
+				Value: 10 * time.Second,
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "How long to follow each run before failing it.",
+				Value: time.Hour,
+			},
+		},
+	}
+}
+
+// runSourceRunTrigger is the source of runs queued by a run trigger, which this go-tfe version
+// doesn't define.
+const runSourceRunTrigger tfe.RunSource = "tfe-run-trigger"
+
+// triggeredRunWait is how long a run trigger target is polled for the run its upstream's apply
+// queued before a run is created instead.
+const triggeredRunWait = time.Minute
+
+type orchestratedWorkspace struct {
+	Workspace string
+	Level     int
+	RunID     string `json:",omitempty"`
+	Result    string
+	Error     string `json:",omitempty"`
+
+	appliedAt time.Time
+}
+
+// Orchestration results.
+const (
+	resultApplied   = "applied"
+	resultNoChanges = "no changes"
+	resultFailed    = "failed"
+	resultSkipped   = "skipped"
+	resultPlanned   = "planned"
+)
+
+func (tfc *TFCClient) runsOrchestrate(ctx *cli.Context) error {
+	all, err := tfc.listWorkspaces(ctx.Context, &tfe.WorkspaceListOptions{})
+	if err != nil {
+		return err
+	}
+
+	var roots []*tfe.Workspace
+	for _, r := range ctx.StringSlice("root") {
+		ws, err := tfc.resolveWorkspace(ctx.Context, r)
+		if err != nil {
+			return err
+		}
+		roots = append(roots, ws)
+	}
+
+	logf(ctx, "building the dependency graph downstream of %d workspaces", len(roots))
+
+	g, err := tfc.buildWorkspaceGraph(ctx.Context, ctx.Int("concurrency"), roots, all, true)
+	if err != nil {
+		return err
+	}
+
+	if cycles := g.cycles(); len(cycles) > 0 {
+		var desc []string
+		for _, c := range cycles {
+			names := make([]string, len(c))
+			for i := range c {
+				names[i] = g.name(c[i])
+			}
+			desc = append(desc, strings.Join(names, " -> "))
+		}
+		return fmt.Errorf("the dependency graph has cycles, nothing was run: %s", strings.Join(desc, "; "))
+	}
+
+	levels, _ := g.levels()
+
+	results := map[string]*orchestratedWorkspace{}
+	var order []*orchestratedWorkspace
+	for i, level := range levels {
+		for _, id := range level {
+			r := &orchestratedWorkspace{Workspace: g.name(id), Level: i, Result: resultPlanned}
+			results[id] = r
+			order = append(order, r)
+		}
+	}
+
+	if ctx.Bool("dry-run") {
+		return printOrchestration(order)
+	}
+
+	started := time.Now()
+	var mu sync.Mutex
+
+	for i, level := range levels {
+		logf(ctx, "level %d: %d workspaces", i, len(level))
+
+		err := forEachParallel(ctx.Context, ctx.Int("concurrency"), level, func(c context.Context, id string) error {
+			r := results[id]
+
+			mu.Lock()
+			var failedUpstream []string
+			for _, up := range g.upstream(id) {
+				if u, ok := results[up]; ok && (u.Result == resultFailed || u.Result == resultSkipped) {
+					failedUpstream = append(failedUpstream, u.Workspace)
+				}
+			}
+
+			// Only an upstream apply through a run trigger queues a run here, and only the run queued
+			// by the last of those applies plans against all of them
+			var since time.Time
+			for _, e := range g.Edges {
+				if u, ok := results[e.From]; ok && e.To == id && e.Kind == edgeRunTrigger && u.Result == resultApplied && u.appliedAt.After(since) {
+					since = u.appliedAt
+				}
+			}
+			mu.Unlock()
+
+			if len(failedUpstream) > 0 {
+				mu.Lock()
+				r.Result, r.Error = resultSkipped, fmt.Sprintf("upstream %s didn't apply", strings.Join(failedUpstream, ", "))
+				mu.Unlock()
+				logf(ctx, "%s: skipped, %s", r.Workspace, r.Error)
+				return nil
+			}
+
+			run, result, err := tfc.orchestrateWorkspace(c, ctx, g.Workspaces[id], started, since)
+
+			mu.Lock()
+			defer mu.Unlock()
+			r.Result = result
+			if run != nil {
+				r.RunID = run.ID
+				if result == resultApplied {
+					r.appliedAt = runAppliedAt(run)
+				}
+			}
+			if err != nil {
+				r.Error = err.Error()
+			}
+			logf(ctx, "%s: %s %s", r.Workspace, r.Result, r.Error)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := printOrchestration(order); err != nil {
+		return err
+	}
+
+	for _, r := range order {
+		if r.Result == resultFailed || r.Result == resultSkipped {
+			return cli.Exit("", 1)
+		}
+	}
+	return nil
+}
+
+// orchestrateWorkspace applies a run in the workspace and waits for it. An upstream apply queues
+// a run in its run trigger targets itself, so when since is set, the time of the last upstream
+// apply through a run trigger, the workspace is polled briefly for the run queued by that apply
+// and it is followed instead of creating another.
+func (tfc *TFCClient) orchestrateWorkspace(c context.Context, ctx *cli.Context, ws *tfe.Workspace, started, since time.Time) (*tfe.Run, string, error) {
+	var run *tfe.Run
+
+	if !since.IsZero() {
+		var err error
+		if run, err = tfc.waitForTriggeredRun(c, ctx, ws, started, since); err != nil {
+			return nil, resultFailed, err
+		}
+		if run == nil {
+			logf(ctx, "%s: no run trigger run after %s, creating one", ws.Name, triggeredRunWait)
+		}
+	}
+
+	if run == nil {
+		var err error
+		run, err = tfc.Client.Runs.Create(c, tfe.RunCreateOptions{
+			Workspace: ws,
+			Message:   ptrString(ctx.String("message")),
+		})
+		if err != nil {
+			return nil, resultFailed, fmt.Errorf("creating run: %w", err)
+		}
+	}
+
+	deadline := time.Now().Add(ctx.Duration("timeout"))
+	confirmed := false
+	for {
+		switch run.Status {
+		case tfe.RunApplied:
+			return run, resultApplied, nil
+		case tfe.RunPlannedAndFinished:
+			return run, resultNoChanges, nil
+		case tfe.RunErrored, tfe.RunCanceled, tfe.RunDiscarded, "force_canceled", tfe.RunPolicySoftFailed:
+			return run, resultFailed, fmt.Errorf("run %s", run.Status)
+		}
+
+		if !confirmed && run.Actions != nil && run.Actions.IsConfirmable {
+			confirmed = true
+			if err := tfc.Client.Runs.Apply(c, run.ID, tfe.RunApplyOptions{Comment: ptrString(ctx.String("message"))}); err != nil {
+				return run, resultFailed, fmt.Errorf("applying run: %w", err)
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return run, resultFailed, fmt.Errorf("still %s after %s", run.Status, ctx.Duration("timeout"))
+		}
+
+		select {
+		case <-c.Done():
+			return run, resultFailed, c.Err()
+		case <-time.After(ctx.Duration("poll")):
+		}
+
+		latest, err := tfc.Client.Runs.Read(c, run.ID)
+		if err != nil {
+			return run, resultFailed, fmt.Errorf("reading run: %w", err)
+		}
+		run = latest
+	}
+}
+
+// waitForTriggeredRun returns the plan and apply run a run trigger queued in the workspace since
+// the last upstream apply, or nil if none shows up within triggeredRunWait. Runs triggered earlier
+// in the orchestration planned against stale upstream state, so they are discarded.
+func (tfc *TFCClient) waitForTriggeredRun(c context.Context, ctx *cli.Context, ws *tfe.Workspace, started, since time.Time) (*tfe.Run, error) {
+	deadline := time.Now().Add(triggeredRunWait)
+	stale := map[string]bool{}
+	for {
+		rl, err := tfc.Client.Runs.List(c, ws.ID, &tfe.RunListOptions{
+			ListOptions: tfe.ListOptions{PageSize: 5},
+			Source:      string(runSourceRunTrigger),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("listing runs: %w", err)
+		}
+
+		var found *tfe.Run
+		for _, run := range rl.Items {
+			if run.Source != runSourceRunTrigger || run.PlanOnly || !run.CreatedAt.After(started) {
+				continue
+			}
+
+			if !run.CreatedAt.Before(since) {
+				if found == nil {
+					found = run
+				}
+				continue
+			}
+
+			if !stale[run.ID] {
+				stale[run.ID] = true
+				if err := tfc.discardStaleRun(c, ctx, ws, run); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if found != nil {
+			return found, nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, nil
+		}
+
+		select {
+		case <-c.Done():
+			return nil, c.Err()
+		case <-time.After(ctx.Duration("poll")):
+		}
+	}
+}
+
+// discardStaleRun discards or cancels a run queued by an earlier upstream apply, so it neither
+// applies stale changes nor holds up the run that replaces it.
+func (tfc *TFCClient) discardStaleRun(c context.Context, ctx *cli.Context, ws *tfe.Workspace, run *tfe.Run) error {
+	if run.Actions == nil {
+		return nil
+	}
+
+	comment := ptrString("superseded by a later upstream apply in tfc-cli runs orchestrate")
+	switch {
+	case run.Actions.IsDiscardable:
+		if err := tfc.Client.Runs.Discard(c, run.ID, tfe.RunDiscardOptions{Comment: comment}); err != nil {
+			return fmt.Errorf("discarding stale run %s: %w", run.ID, err)
+		}
+	case run.Actions.IsCancelable:
+		if err := tfc.Client.Runs.Cancel(c, run.ID, tfe.RunCancelOptions{Comment: comment}); err != nil {
+			return fmt.Errorf("canceling stale run %s: %w", run.ID, err)
+		}
+	default:
+		return nil
+	}
+
+	logf(ctx, "%s: discarded run %s, it was triggered before the last upstream apply", ws.Name, run.ID)
+	return nil
+}
+
+// runAppliedAt returns when the run finished applying, or now if the API didn't say.
+func runAppliedAt(run *tfe.Run) time.Time {
+	if run.StatusTimestamps != nil && !run.StatusTimestamps.AppliedAt.IsZero() {
+		return run.StatusTimestamps.AppliedAt
+	}
+	return time.Now()
+}
+
+func printOrchestration(order []*orchestratedWorkspace) error {
+	r, err := json.MarshalIndent(order, "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+	return nil
+}
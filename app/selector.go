@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+// A workspace selector is a list of key=value terms that must all match:
+//
+//	name=<glob>          workspace name, where only * is special, e.g. name=api-*
+//	id=<id,...>          exact workspace ids, may be repeated
+//	search=<text>        partial workspace name, filtered by the API
+//	tags=<a,b>           workspaces with all of these tags, filtered by the API
+//	exclude-tags=<a,b>   workspaces with none of these tags, filtered by the API
+func selectorFlag() cli.Flag {
+	return &cli.StringSliceFlag{
+		Name:    "selector",
+		Aliases: []string{"l"},
+		Usage: "key=value terms selecting workspaces, all of which must match: name=<glob>, id=<id,...>, search=<partial name>, " +
+			"tags=<tag,...>, exclude-tags=<tag,...>. Every workspace when omitted.",
+	}
+}
+
+type workspaceSelector struct {
+	opts  tfe.WorkspaceListOptions
+	names []string
+	ids   map[string]bool
+}
+
+func parseWorkspaceSelector(terms []string) (*workspaceSelector, error) {
+	// Slice flags split on commas, so glue id and tag lists back onto the term they came from
+	var joined []string
+	for _, t := range terms {
+		if !strings.Contains(t, "=") && len(joined) > 0 {
+			joined[len(joined)-1] += "," + t
+			continue
+		}
+		joined = append(joined, t)
+	}
+
+	s := &workspaceSelector{ids: map[string]bool{}}
+
+	for _, t := range joined {
+		k, v, ok := strings.Cut(t, "=")
+		if !ok || v == "" {
+			return nil, fmt.Errorf("invalid selector term %q, expected key=value", t)
+		}
+
+		switch k {
+		case "name":
+			s.names = append(s.names, v)
+		case "id":
+			for _, id := range strings.Split(v, ",") {
+				if id != "" {
+					s.ids[id] = true
+				}
+			}
+		case "search":
+			s.opts.Search = v
+		case "tags", "tag":
+			s.opts.Tags = strings.Trim(strings.Join([]string{s.opts.Tags, v}, ","), ",")
+		case "exclude-tags":
+			s.opts.ExcludeTags = strings.Trim(strings.Join([]string{s.opts.ExcludeTags, v}, ","), ",")
+		default:
+			return nil, fmt.Errorf("selector key not recognized: %s", k)
+		}
+	}
+
+	return s, nil
+}
+
+func (s *workspaceSelector) matches(ws *tfe.Workspace) bool {
+	if len(s.ids) > 0 && !s.ids[ws.ID] {
+		return false
+	}
+
+	for _, n := range s.names {
+		if !globMatch(n, ws.Name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// selectWorkspaces returns every workspace in the organization matching the selector terms.
+func (tfc *TFCClient) selectWorkspaces(ctx context.Context, terms []string) ([]*tfe.Workspace, error) {
+	s, err := parseWorkspaceSelector(terms)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := tfc.listWorkspaces(ctx, &s.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]*tfe.Workspace, 0, len(all))
+	for _, ws := range all {
+		if s.matches(ws) {
+			selected = append(selected, ws)
+		}
+	}
+
+	return selected, nil
+}
@@ -0,0 +1,324 @@
+package app
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/time/rate"
+)
+
+const backupManifestName = "manifest.json"
+
+func (tfc *TFCClient) StateBackupCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "backup",
+		Usage:    "Download the current state of every selected workspace into a directory with a manifest.",
+		Category: "state",
+		Action:   tfc.stateBackup,
+		Flags: []cli.Flag{
+			selectorFlag(),
+			&cli.StringFlag{
+				Name:     "out",
+				Aliases:  []string{"o"},
+				Usage:    "(Required) Directory to write the backup to. Workspaces already in its manifest are skipped, so an interrupted backup can be resumed by running it again.",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "archive",
+				Usage: "Also write the backup directory to this tar.gz file.",
+			},
+			&cli.Float64Flag{
+				Name:  "rate",
+				Usage: "Maximum API requests per second.",
+				Value: 10,
+			},
+			concurrencyFlag(),
+		},
+	}
+}
+
+type backupManifest struct {
+	Organization string
+	UpdatedAt    time.Time
+	Workspaces   []*backupManifestEntry
+}
+
+type backupManifestEntry struct {
+	WorkspaceID    string
+	Workspace      string
+	StateVersionID string
+	Serial         int64
+	Lineage        string
+	File           string
+	SHA256         string
+	StateCreatedAt time.Time
+	BackedUpAt     time.Time
+}
+
+func (tfc *TFCClient) stateBackup(ctx *cli.Context) error {
+	if ctx.Float64("rate") <= 0 {
+		return fmt.Errorf("--rate must be greater than 0")
+	}
+
+	dir := ctx.String("out")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	manifest, err := readBackupManifest(dir)
+	if err != nil {
+		return err
+	}
+	manifest.Organization = tfc.Cfg.OrgName
+
+	done := map[string]bool{}
+	for _, e := range manifest.Workspaces {
+		if backupFileIntact(dir, e) {
+			done[e.WorkspaceID] = true
+		}
+	}
+
+	workspaces, err := tfc.selectWorkspaces(ctx.Context, ctx.StringSlice("selector"))
+	if err != nil {
+		return err
+	}
+
+	var todo []*tfe.Workspace
+	for _, ws := range workspaces {
+		if !done[ws.ID] {
+			todo = append(todo, ws)
+		}
+	}
+
+	logf(ctx, "backing up %d workspaces, %d already in %s", len(todo), len(workspaces)-len(todo), dir)
+
+	var (
+		mu       sync.Mutex
+		limiter  = rate.NewLimiter(rate.Limit(ctx.Float64("rate")), 1)
+		noState  int
+		failed   int
+		backedUp int
+	)
+
+	err = forEachParallel(ctx.Context, ctx.Int("concurrency"), todo, func(c context.Context, ws *tfe.Workspace) error {
+		e, err := tfc.backupWorkspace(c, limiter, dir, ws)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case errors.Is(err, tfe.ErrResourceNotFound):
+			logf(ctx, "%s has no state, skipping", ws.Name)
+			noState++
+			return nil
+		case err != nil:
+			if c.Err() != nil {
+				return err
+			}
+			logf(ctx, "%s: %s", ws.Name, err)
+			failed++
+			return nil
+		}
+
+		logf(ctx, "backed up %s serial %d", ws.Name, e.Serial)
+		backedUp++
+
+		// Record progress as we go so an interrupted backup can pick up where it stopped
+		manifest.add(e)
+		return writeBackupManifest(dir, manifest)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("backed up %d workspaces, %d already backed up, %d without state, %d failed\n",
+		backedUp, len(workspaces)-len(todo), noState, failed)
+
+	if ctx.IsSet("archive") {
+		if err := writeBackupArchive(dir, ctx.String("archive")); err != nil {
+			return err
+		}
+		fmt.Printf("wrote archive %s\n", ctx.String("archive"))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to back up %d workspaces, run the backup again to retry them", failed)
+	}
+	return nil
+}
+
+func (tfc *TFCClient) backupWorkspace(ctx context.Context, limiter *rate.Limiter, dir string, ws *tfe.Workspace) (*backupManifestEntry, error) {
+	if err := limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	sv, err := tfc.Client.StateVersions.ReadCurrent(ctx, ws.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	b, err := tfc.downloadState(ctx, sv)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the lineage is needed here, and older state formats have it too
+	var meta struct {
+		Lineage string `json:"lineage"`
+	}
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return nil, fmt.Errorf("state version %s: invalid state: %w", sv.ID, err)
+	}
+
+	file := ws.Name + ".tfstate"
+	if err := writeFileAtomic(filepath.Join(dir, file), b); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(b)
+	return &backupManifestEntry{
+		WorkspaceID:    ws.ID,
+		Workspace:      ws.Name,
+		StateVersionID: sv.ID,
+		Serial:         sv.Serial,
+		Lineage:        meta.Lineage,
+		File:           file,
+		SHA256:         hex.EncodeToString(sum[:]),
+		StateCreatedAt: sv.CreatedAt,
+		BackedUpAt:     time.Now().UTC(),
+	}, nil
+}
+
+func (m *backupManifest) add(e *backupManifestEntry) {
+	for i := range m.Workspaces {
+		if m.Workspaces[i].WorkspaceID == e.WorkspaceID {
+			m.Workspaces[i] = e
+			return
+		}
+	}
+	m.Workspaces = append(m.Workspaces, e)
+}
+
+func readBackupManifest(dir string) (*backupManifest, error) {
+	b, err := os.ReadFile(filepath.Join(dir, backupManifestName))
+	if errors.Is(err, os.ErrNotExist) {
+		return &backupManifest{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m backupManifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("invalid backup manifest: %w", err)
+	}
+
+	return &m, nil
+}
+
+func writeBackupManifest(dir string, m *backupManifest) error {
+	sort.Slice(m.Workspaces, func(i, j int) bool { return m.Workspaces[i].Workspace < m.Workspaces[j].Workspace })
+	m.UpdatedAt = time.Now().UTC()
+
+	b, err := json.MarshalIndent(m, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filepath.Join(dir, backupManifestName), b)
+}
+
+// backupFileIntact reports whether the state file recorded in the manifest is still on disk unchanged.
+func backupFileIntact(dir string, e *backupManifestEntry) bool {
+	b, err := os.ReadFile(filepath.Join(dir, e.File))
+	if err != nil {
+		return false
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]) == e.SHA256
+}
+
+// writeFileAtomic writes through a temporary file so an interruption never leaves a partial file behind.
+func writeFileAtomic(name string, b []byte) error {
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+func writeBackupArchive(dir, archive string) error {
+	f, err := os.OpenFile(archive, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	root := filepath.Base(filepath.Clean(dir))
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, de := range entries {
+		if de.IsDir() || filepath.Ext(de.Name()) == ".tmp" {
+			continue
+		}
+
+		info, err := de.Info()
+		if err != nil {
+			return err
+		}
+
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = root + "/" + de.Name()
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		src, err := os.Open(filepath.Join(dir, de.Name()))
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(tw, src)
+		src.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
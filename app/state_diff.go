@@ -0,0 +1,312 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+func (tfc *TFCClient) StateDiffCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "diff",
+		Usage:     "Show the resources that changed between two state versions of a workspace.",
+		UsageText: "tfc-client state diff [options] <workspace>",
+		Category:  "state",
+		Action:    tfc.stateDiff,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "serial of the older state version. Defaults to the version before --to.",
+			},
+			&cli.StringFlag{
+				Name:  "to",
+				Usage: "serial of the newer state version, or current.",
+				Value: "current",
+			},
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Usage:   "text or json",
+				Value:   "text",
+			},
+		},
+	}
+}
+
+type stateDiffVersion struct {
+	ID     string
+	Serial int64
+}
+
+type stateAttrChange struct {
+	Path string
+	Old  interface{} `json:",omitempty"`
+	New  interface{} `json:",omitempty"`
+}
+
+type stateResourceChange struct {
+	Address    string
+	Attributes []stateAttrChange
+}
+
+type stateDiffResponse struct {
+	Workspace string
+	From      stateDiffVersion
+	To        stateDiffVersion
+	Added     []string
+	Removed   []string
+	Changed   []stateResourceChange
+	Unchanged int
+}
+
+func (tfc *TFCClient) stateDiff(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("expected a workspace name or id")
+	}
+
+	format := ctx.String("format")
+	if format != "text" && format != "json" {
+		return fmt.Errorf("format not recognized: %s", format)
+	}
+
+	ws, err := tfc.resolveWorkspace(ctx.Context, ctx.Args().First())
+	if err != nil {
+		return err
+	}
+
+	from, to, err := tfc.stateDiffVersions(ctx, ws, ctx.String("from"), ctx.String("to"))
+	if err != nil {
+		return err
+	}
+
+	logf(ctx, "comparing %s serial %d to serial %d", ws.Name, from.Serial, to.Serial)
+
+	states := make([]*tfState, 2)
+	for i, sv := range []*tfe.StateVersion{from, to} {
+		b, err := tfc.downloadState(ctx.Context, sv)
+		if err != nil {
+			return err
+		}
+
+		if states[i], err = parseState(b); err != nil {
+			return fmt.Errorf("state version %s: %w", sv.ID, err)
+		}
+	}
+
+	d := diffStates(states[0], states[1])
+	d.Workspace = ws.Name
+	d.From = stateDiffVersion{ID: from.ID, Serial: from.Serial}
+	d.To = stateDiffVersion{ID: to.ID, Serial: to.Serial}
+
+	if format == "json" {
+		r, err := json.MarshalIndent(d, "", "    ")
+		if err != nil {
+			return nil
+		}
+
+		fmt.Println(string(r))
+		return nil
+	}
+
+	fmt.Printf("%s: serial %d => %d\n", d.Workspace, d.From.Serial, d.To.Serial)
+	for _, a := range d.Added {
+		fmt.Printf("+ %s\n", a)
+	}
+	for _, a := range d.Removed {
+		fmt.Printf("- %s\n", a)
+	}
+	for _, c := range d.Changed {
+		fmt.Printf("~ %s\n", c.Address)
+		for _, a := range c.Attributes {
+			fmt.Printf("    %s: %s => %s\n", a.Path, diffValueString(a.Old), diffValueString(a.New))
+		}
+	}
+	fmt.Printf("%d added, %d removed, %d changed, %d unchanged\n", len(d.Added), len(d.Removed), len(d.Changed), d.Unchanged)
+
+	return nil
+}
+
+// stateDiffVersions finds the state versions to compare. to is a serial or "current" and
+// from defaults to the state version right before to.
+func (tfc *TFCClient) stateDiffVersions(ctx *cli.Context, ws *tfe.Workspace, from, to string) (*tfe.StateVersion, *tfe.StateVersion, error) {
+	parseSerial := func(flag, v string) (int64, error) {
+		s, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("--%s must be a serial: %s", flag, v)
+		}
+		return s, nil
+	}
+
+	var (
+		toSV, fromSV         *tfe.StateVersion
+		toSerial, fromSerial int64 = -1, -1
+		err                  error
+	)
+
+	if to == "current" || to == "" {
+		if toSV, err = tfc.Client.StateVersions.ReadCurrent(ctx.Context, ws.ID); err != nil {
+			return nil, nil, fmt.Errorf("reading current state version of %s: %w", ws.Name, err)
+		}
+		toSerial = toSV.Serial
+	} else if toSerial, err = parseSerial("to", to); err != nil {
+		return nil, nil, err
+	}
+
+	if from != "" {
+		if fromSerial, err = parseSerial("from", from); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Versions are listed newest first, so walk pages until both ends have been seen
+	opts := &tfe.StateVersionListOptions{
+		ListOptions:  tfe.ListOptions{PageSize: 100},
+		Organization: tfc.Cfg.OrgName,
+		Workspace:    ws.Name,
+	}
+
+	for fromSV == nil || toSV == nil {
+		svl, err := tfc.Client.StateVersions.List(ctx.Context, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, sv := range svl.Items {
+			switch {
+			case sv.Serial == toSerial:
+				if toSV == nil {
+					toSV = sv
+				}
+			case fromSerial >= 0 && sv.Serial == fromSerial:
+				fromSV = sv
+			case fromSerial < 0 && toSV != nil && sv.Serial < toSerial:
+				fromSV = sv
+			}
+
+			if fromSV != nil && toSV != nil {
+				break
+			}
+		}
+
+		if svl.Pagination == nil || svl.CurrentPage >= svl.TotalPages {
+			break
+		}
+		opts.PageNumber = svl.NextPage
+	}
+
+	if toSV == nil {
+		return nil, nil, fmt.Errorf("no state version with serial %d in %s", toSerial, ws.Name)
+	}
+
+	if fromSV == nil {
+		if fromSerial >= 0 {
+			return nil, nil, fmt.Errorf("no state version with serial %d in %s", fromSerial, ws.Name)
+		}
+		return nil, nil, fmt.Errorf("no state version before serial %d in %s", toSerial, ws.Name)
+	}
+
+	return fromSV, toSV, nil
+}
+
+// diffStates compares resource instances by address, which includes the index key.
+func diffStates(from, to *tfState) *stateDiffResponse {
+	index := func(s *tfState) map[string]*tfStateEntry {
+		m := map[string]*tfStateEntry{}
+		for _, e := range s.entries() {
+			m[e.Address] = e
+		}
+		return m
+	}
+
+	fm, tm := index(from), index(to)
+
+	d := &stateDiffResponse{
+		Added:   []string{},
+		Removed: []string{},
+		Changed: []stateResourceChange{},
+	}
+
+	for addr, te := range tm {
+		fe, ok := fm[addr]
+		if !ok {
+			d.Added = append(d.Added, addr)
+			continue
+		}
+
+		changes := diffEntries(fe, te)
+		if len(changes) == 0 {
+			d.Unchanged++
+			continue
+		}
+
+		d.Changed = append(d.Changed, stateResourceChange{Address: addr, Attributes: changes})
+	}
+
+	for addr := range fm {
+		if _, ok := tm[addr]; !ok {
+			d.Removed = append(d.Removed, addr)
+		}
+	}
+
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Slice(d.Changed, func(i, j int) bool { return d.Changed[i].Address < d.Changed[j].Address })
+
+	return d
+}
+
+func diffEntries(from, to *tfStateEntry) []stateAttrChange {
+	fa, ta := from.flatAttrs(), to.flatAttrs()
+	fs, ts := from.sensitiveAttrs(), to.sensitiveAttrs()
+
+	paths := map[string]bool{}
+	for p := range fa {
+		paths[p] = true
+	}
+	for p := range ta {
+		paths[p] = true
+	}
+
+	var changes []stateAttrChange
+	for p := range paths {
+		fv, inFrom := fa[p]
+		tv, inTo := ta[p]
+
+		if inFrom && inTo && attrString(fv) == attrString(tv) {
+			continue
+		}
+
+		top := strings.SplitN(p, ".", 2)[0]
+		if fs[top] || ts[top] {
+			fv, tv = "<sensitive>", "<sensitive>"
+		}
+
+		c := stateAttrChange{Path: p}
+		if inFrom {
+			c.Old = fv
+		}
+		if inTo {
+			c.New = tv
+		}
+		changes = append(changes, c)
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+func diffValueString(v interface{}) string {
+	if v == nil {
+		return "(none)"
+	}
+
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	return attrString(v)
+}
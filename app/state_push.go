@@ -0,0 +1,166 @@
+package app
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+func (tfc *TFCClient) StatePushCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "push",
+		Usage:    "Upload a local state file as a new state version of a workspace.",
+		Category: "state",
+		Action:   tfc.statePush,
+		Flags: []cli.Flag{
+			stateWorkspaceFlag(true),
+			&cli.StringFlag{
+				Name:     "file",
+				Aliases:  []string{"f"},
+				Usage:    "(Required) state file to upload, or \"-\" for stdin.",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Upload even when the lineage differs from the current state or the serial isn't greater. This can overwrite good state, use with caution.",
+			},
+		},
+	}
+}
+
+func (tfc *TFCClient) statePush(ctx *cli.Context) error {
+	b, err := readInput(ctx.String("file"))
+	if err != nil {
+		return err
+	}
+
+	var local struct {
+		Serial  *int64 `json:"serial"`
+		Lineage string `json:"lineage"`
+	}
+	if err := json.Unmarshal(b, &local); err != nil {
+		return fmt.Errorf("invalid state file: %w", err)
+	}
+
+	if local.Serial == nil || local.Lineage == "" {
+		return fmt.Errorf("state file is missing its serial or lineage")
+	}
+
+	ws, err := tfc.resolveWorkspace(ctx.Context, ctx.String("workspace"))
+	if err != nil {
+		return err
+	}
+
+	// Cancel the upload on interrupt instead of exiting, so the workspace still gets unlocked
+	c, stop := signal.NotifyContext(ctx.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if _, err := tfc.Client.Workspaces.Lock(c, ws.ID, tfe.WorkspaceLockOptions{
+		Reason: ptrString("tfc-cli state push"),
+	}); err != nil {
+		return fmt.Errorf("locking workspace %s: %w", ws.Name, err)
+	}
+
+	logf(ctx, "locked workspace %s", ws.Name)
+
+	sv, notes, err := tfc.pushState(c, ws, b, *local.Serial, local.Lineage, ctx.Bool("force"))
+	for _, n := range notes {
+		logf(ctx, "%s", n)
+	}
+
+	// The push context may already be cancelled, unlock regardless
+	uc, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, uerr := tfc.Client.Workspaces.Unlock(uc, ws.ID); uerr != nil {
+		uerr = fmt.Errorf("unlocking workspace %s: %w", ws.Name, uerr)
+		if err != nil {
+			return fmt.Errorf("%w; %s", err, uerr)
+		}
+		return uerr
+	}
+
+	logf(ctx, "unlocked workspace %s", ws.Name)
+
+	if err != nil {
+		return err
+	}
+
+	r, err := json.MarshalIndent(newStateVersionResponse(sv), "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Printf("created state version:\n%s\n", string(r))
+	return nil
+}
+
+// pushState checks the local state against the workspace's current state and uploads it, along
+// with notes on anything force pushed over. The workspace must already be locked.
+func (tfc *TFCClient) pushState(ctx context.Context, ws *tfe.Workspace, state []byte, serial int64, lineage string, force bool) (*tfe.StateVersion, []string, error) {
+	var notes []string
+
+	current, err := tfc.Client.StateVersions.ReadCurrent(ctx, ws.ID)
+	switch {
+	case errors.Is(err, tfe.ErrResourceNotFound):
+		notes = append(notes, fmt.Sprintf("%s has no state yet", ws.Name))
+	case err != nil:
+		return nil, nil, fmt.Errorf("reading current state version of %s: %w", ws.Name, err)
+	default:
+		cb, err := tfc.downloadState(ctx, current)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var remote struct {
+			Lineage string `json:"lineage"`
+		}
+		if err := json.Unmarshal(cb, &remote); err != nil {
+			return nil, nil, fmt.Errorf("state version %s: invalid state: %w", current.ID, err)
+		}
+
+		if remote.Lineage != lineage {
+			if !force {
+				return nil, nil, fmt.Errorf("lineage %s doesn't match the current state's %s, pass --force to push anyway", lineage, remote.Lineage)
+			}
+			notes = append(notes, fmt.Sprintf("pushing over lineage %s with %s", remote.Lineage, lineage))
+		}
+
+		if serial <= current.Serial {
+			if !force {
+				return nil, nil, fmt.Errorf("serial %d isn't greater than the current serial %d, pass --force to push anyway", serial, current.Serial)
+			}
+			notes = append(notes, fmt.Sprintf("pushing serial %d over current serial %d", serial, current.Serial))
+		}
+	}
+
+	sum := md5.Sum(state)
+	opts := tfe.StateVersionCreateOptions{
+		Lineage: ptrString(lineage),
+		MD5:     ptrString(hex.EncodeToString(sum[:])),
+		Serial:  &serial,
+		State:   ptrString(base64.StdEncoding.EncodeToString(state)),
+	}
+
+	if force {
+		opts.Force = ptrBool(true)
+	}
+
+	sv, err := tfc.Client.StateVersions.Create(ctx, ws.ID, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating state version: %w", err)
+	}
+
+	return sv, notes, nil
+}
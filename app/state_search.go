@@ -0,0 +1,272 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+func (tfc *TFCClient) StateSearchCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "search",
+		Usage:    "Find which workspaces manage a resource by searching their current state.",
+		Category: "state",
+		Action:   tfc.stateSearch,
+		Flags: []cli.Flag{
+			selectorFlag(),
+			&cli.StringFlag{
+				Name:    "type",
+				Aliases: []string{"t"},
+				Usage:   "resource type, * matches anything. e.g. aws_s3_bucket or aws_iam_*",
+			},
+			&cli.StringFlag{
+				Name:  "address",
+				Usage: "resource address, * matches anything. e.g. module.vpc.*",
+			},
+			&cli.StringFlag{
+				Name:  "module",
+				Usage: "module path, * matches anything. Pass \"root\" for resources outside of modules.",
+			},
+			&cli.StringFlag{
+				Name:  "provider",
+				Usage: "substring of the provider source address. e.g. hashicorp/aws",
+			},
+			&cli.StringFlag{
+				Name:  "mode",
+				Usage: "managed or data. Both when omitted.",
+			},
+			&cli.StringSliceFlag{
+				Name:    "attr",
+				Aliases: []string{"a"},
+				Usage:   "key=value to match an attribute exactly, or key~=regex. Nested attributes use dots, e.g. tags.Name=api. May be repeated.",
+			},
+			&cli.StringFlag{
+				Name:  "id",
+				Usage: "shorthand for --attr id=<id>",
+			},
+			&cli.StringSliceFlag{
+				Name:  "show-attr",
+				Usage: "Additional attributes to print for every match. Sensitive attributes are masked.",
+			},
+			concurrencyFlag(),
+		},
+	}
+}
+
+// stateAttrFilter matches one attribute of a resource instance.
+type stateAttrFilter struct {
+	path  string
+	value string
+	re    *regexp.Regexp
+}
+
+func (f *stateAttrFilter) matches(e *tfStateEntry) bool {
+	v, ok := e.attr(f.path)
+	if !ok {
+		return false
+	}
+
+	if f.re != nil {
+		return f.re.MatchString(attrString(v))
+	}
+	return attrString(v) == f.value
+}
+
+type stateSearchMatch struct {
+	Workspace   string
+	WorkspaceID string
+	Serial      int64
+	Address     string
+	Type        string
+	Provider    string
+	Module      string                 `json:",omitempty"`
+	ID          string                 `json:",omitempty"`
+	Attributes  map[string]interface{} `json:",omitempty"`
+}
+
+func (tfc *TFCClient) stateSearch(ctx *cli.Context) error {
+	var filters []*stateAttrFilter
+
+	attrs := ctx.StringSlice("attr")
+	if ctx.IsSet("id") {
+		attrs = append(attrs, "id="+ctx.String("id"))
+	}
+
+	for _, a := range attrs {
+		if k, expr, ok := strings.Cut(a, "~="); ok {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return fmt.Errorf("invalid attribute regex %q: %w", a, err)
+			}
+			filters = append(filters, &stateAttrFilter{path: k, re: re})
+			continue
+		}
+
+		k, v, ok := strings.Cut(a, "=")
+		if !ok {
+			return fmt.Errorf("invalid attribute filter %q, expected key=value or key~=regex", a)
+		}
+		filters = append(filters, &stateAttrFilter{path: k, value: v})
+	}
+
+	if mode := ctx.String("mode"); mode != "" && mode != "managed" && mode != "data" {
+		return fmt.Errorf("mode not recognized: %s", mode)
+	}
+
+	match := func(e *tfStateEntry) bool {
+		if t := ctx.String("type"); t != "" && !globMatch(t, e.Type) {
+			return false
+		}
+		if a := ctx.String("address"); a != "" && !globMatch(a, e.Address) {
+			return false
+		}
+		if m := ctx.String("module"); m != "" {
+			if m == "root" && e.Module != "" || m != "root" && !globMatch(m, e.Module) {
+				return false
+			}
+		}
+		if p := ctx.String("provider"); p != "" && !strings.Contains(e.Provider, p) {
+			return false
+		}
+		if m := ctx.String("mode"); m != "" && e.Mode != m {
+			return false
+		}
+		for _, f := range filters {
+			if !f.matches(e) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Print the attributes that were searched for along with any extra ones asked for
+	show := ctx.StringSlice("show-attr")
+	for _, f := range filters {
+		show = append(show, f.path)
+	}
+
+	workspaces, err := tfc.selectWorkspaces(ctx.Context, ctx.StringSlice("selector"))
+	if err != nil {
+		return err
+	}
+
+	logf(ctx, "searching the current state of %d workspaces", len(workspaces))
+
+	var (
+		mu      sync.Mutex
+		matches = []stateSearchMatch{}
+		failed  int
+	)
+
+	err = forEachParallel(ctx.Context, ctx.Int("concurrency"), workspaces, func(c context.Context, ws *tfe.Workspace) error {
+		state, serial, err := tfc.currentState(c, ws)
+		if err != nil {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if errors.Is(err, tfe.ErrResourceNotFound) {
+				logf(ctx, "%s has no state, skipping", ws.Name)
+				return nil
+			}
+
+			logf(ctx, "%s: %s", ws.Name, err)
+			failed++
+			return nil
+		}
+
+		var found []stateSearchMatch
+		for _, e := range state.entries() {
+			if !match(e) {
+				continue
+			}
+
+			m := stateSearchMatch{
+				Workspace:   ws.Name,
+				WorkspaceID: ws.ID,
+				Serial:      serial,
+				Address:     e.Address,
+				Type:        e.Type,
+				Provider:    e.Provider,
+				Module:      e.Module,
+			}
+
+			if id, ok := e.attr("id"); ok {
+				m.ID = attrString(id)
+			}
+
+			sensitive := e.sensitiveAttrs()
+			for _, p := range show {
+				v, ok := e.attr(p)
+				if !ok {
+					continue
+				}
+
+				if m.Attributes == nil {
+					m.Attributes = map[string]interface{}{}
+				}
+
+				if sensitive[strings.Split(p, ".")[0]] {
+					v = "<sensitive>"
+				}
+				m.Attributes[p] = v
+			}
+
+			found = append(found, m)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		matches = append(matches, found...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Workspace != matches[j].Workspace {
+			return matches[i].Workspace < matches[j].Workspace
+		}
+		return matches[i].Address < matches[j].Address
+	})
+
+	r, err := json.MarshalIndent(matches, "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+
+	if failed > 0 {
+		return fmt.Errorf("failed to search the state of %d workspaces", failed)
+	}
+	return nil
+}
+
+// currentState downloads and parses the current state of a workspace.
+func (tfc *TFCClient) currentState(ctx context.Context, ws *tfe.Workspace) (*tfState, int64, error) {
+	sv, err := tfc.Client.StateVersions.ReadCurrent(ctx, ws.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	b, err := tfc.downloadState(ctx, sv)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	state, err := parseState(b)
+	if err != nil {
+		return nil, 0, fmt.Errorf("state version %s: %w", sv.ID, err)
+	}
+
+	return state, sv.Serial, nil
+}
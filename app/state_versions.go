@@ -0,0 +1,335 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+// StateVersions describes all the state version related methods that
+// the Terraform Enterprise API supports.
+//
+// TFE API docs:
+// https://www.terraform.io/docs/cloud/api/state-versions.html
+//
+//	// List all the state versions for a given workspace.
+//	List(ctx context.Context, options *StateVersionListOptions) (*StateVersionList, error)
+//
+//	// Create a new state version for the given workspace.
+//	Create(ctx context.Context, workspaceID string, options StateVersionCreateOptions) (*StateVersion, error)
+//
+//	// Read a state version by its ID.
+//	Read(ctx context.Context, svID string) (*StateVersion, error)
+//
+//	// ReadCurrent reads the latest available state from the given workspace.
+//	ReadCurrent(ctx context.Context, workspaceID string) (*StateVersion, error)
+//
+//	// Download retrieves the actual stored state of a state version
+//	Download(ctx context.Context, url string) ([]byte, error)
+//
+// StateVersionOutputs describes all the state version output related methods.
+//
+//	// Read a state version output by its ID. Sensitive values are only returned here.
+//	Read(ctx context.Context, outputID string) (*StateVersionOutput, error)
+//
+//	// ReadCurrent reads the current state version outputs for the specified workspace
+//	ReadCurrent(ctx context.Context, workspaceID string) (*StateVersionOutputsList, error)
+
+func stateWorkspaceFlag(required bool) cli.Flag {
+	return &cli.StringFlag{
+		Name:     "workspace",
+		Aliases:  []string{"ws"},
+		Usage:    "name or id of the workspace.",
+		Required: required,
+	}
+}
+
+func (tfc *TFCClient) StateListCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "list",
+		Aliases:  []string{"ls"},
+		Usage:    "List the state versions of a workspace, newest first.",
+		Category: "state",
+		Action:   tfc.stateList,
+		Flags: []cli.Flag{
+			stateWorkspaceFlag(true),
+			&cli.IntFlag{
+				Name:  "page-num",
+				Usage: "The page number to request. The results vary based on the PageSize.",
+			},
+			&cli.IntFlag{
+				Name:  "page-size",
+				Usage: "The number of elements returned in a single page.",
+			},
+		},
+	}
+}
+
+type stateVersionResponse struct {
+	ID                 string
+	Serial             int64
+	CreatedAt          time.Time
+	RunID              string `json:",omitempty"`
+	ResourcesProcessed bool
+	TerraformVersion   string `json:",omitempty"`
+	VCSCommitSHA       string `json:",omitempty"`
+	StateVersion       int    `json:",omitempty"`
+}
+
+func newStateVersionResponse(sv *tfe.StateVersion) stateVersionResponse {
+	r := stateVersionResponse{
+		ID:                 sv.ID,
+		Serial:             sv.Serial,
+		CreatedAt:          sv.CreatedAt,
+		ResourcesProcessed: sv.ResourcesProcessed,
+		TerraformVersion:   sv.TerraformVersion,
+		VCSCommitSHA:       sv.VCSCommitSHA,
+		StateVersion:       sv.StateVersion,
+	}
+
+	if sv.Run != nil {
+		r.RunID = sv.Run.ID
+	}
+
+	return r
+}
+
+func (tfc *TFCClient) stateList(ctx *cli.Context) error {
+	ws, err := tfc.resolveWorkspace(ctx.Context, ctx.String("workspace"))
+	if err != nil {
+		return err
+	}
+
+	svl, err := tfc.Client.StateVersions.List(ctx.Context, &tfe.StateVersionListOptions{
+		ListOptions: tfe.ListOptions{
+			PageNumber: ctx.Int("page-num"),
+			PageSize:   ctx.Int("page-size"),
+		},
+		Organization: tfc.Cfg.OrgName,
+		Workspace:    ws.Name,
+	})
+	if err != nil {
+		return err
+	}
+
+	response := make([]stateVersionResponse, len(svl.Items))
+	for i := range svl.Items {
+		response[i] = newStateVersionResponse(svl.Items[i])
+	}
+
+	r, err := json.MarshalIndent(response, "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+	return nil
+}
+
+func (tfc *TFCClient) StateShowCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "show",
+		Usage:     "Show a state version by id, or the current state version of a workspace.",
+		UsageText: "tfc-client state show [options] <state-version-id|current>",
+		Category:  "state",
+		Action:    tfc.stateShow,
+		Flags:     []cli.Flag{stateWorkspaceFlag(false)},
+	}
+}
+
+func (tfc *TFCClient) stateShow(ctx *cli.Context) error {
+	sv, err := tfc.stateVersionFromArgs(ctx)
+	if err != nil {
+		return err
+	}
+
+	r, err := json.MarshalIndent(newStateVersionResponse(sv), "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+	return nil
+}
+
+func (tfc *TFCClient) StateDownloadCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "download",
+		Usage:     "Download the raw state of a state version, or the current state of a workspace.",
+		UsageText: "tfc-client state download [options] <state-version-id|current>",
+		Category:  "state",
+		Action:    tfc.stateDownload,
+		Flags: []cli.Flag{
+			stateWorkspaceFlag(false),
+			&cli.StringFlag{
+				Name:    "out",
+				Aliases: []string{"o"},
+				Usage:   "File to write the state to. Written to stdout when omitted or \"-\".",
+			},
+		},
+	}
+}
+
+func (tfc *TFCClient) stateDownload(ctx *cli.Context) error {
+	sv, err := tfc.stateVersionFromArgs(ctx)
+	if err != nil {
+		return err
+	}
+
+	state, err := tfc.downloadState(ctx.Context, sv)
+	if err != nil {
+		return err
+	}
+
+	if out := ctx.String("out"); out != "" && out != "-" {
+		// State holds every secret the configuration touches, so keep it private
+		if err := os.WriteFile(out, state, 0600); err != nil {
+			return err
+		}
+
+		logf(ctx, "wrote state version %s (serial %d) to %s", sv.ID, sv.Serial, out)
+		return nil
+	}
+
+	_, err = os.Stdout.Write(state)
+	return err
+}
+
+func (tfc *TFCClient) downloadState(ctx context.Context, sv *tfe.StateVersion) ([]byte, error) {
+	if sv.DownloadURL == "" {
+		return nil, fmt.Errorf("state version %s has no hosted state to download", sv.ID)
+	}
+
+	state, err := tfc.Client.StateVersions.Download(ctx, sv.DownloadURL)
+	if err != nil {
+		return nil, fmt.Errorf("downloading state version %s: %w", sv.ID, err)
+	}
+
+	return state, nil
+}
+
+// stateVersionFromArgs reads the state version named by the first argument, where "current"
+// means the current state version of --workspace.
+func (tfc *TFCClient) stateVersionFromArgs(ctx *cli.Context) (*tfe.StateVersion, error) {
+	if ctx.NArg() != 1 {
+		return nil, fmt.Errorf("expected a state version id or \"current\"")
+	}
+
+	ref := ctx.Args().First()
+	if ref != "current" {
+		sv, err := tfc.Client.StateVersions.Read(ctx.Context, ref)
+		if err != nil {
+			return nil, fmt.Errorf("reading state version %s: %w", ref, err)
+		}
+		return sv, nil
+	}
+
+	if !ctx.IsSet("workspace") {
+		return nil, fmt.Errorf("--workspace is required to read the current state version")
+	}
+
+	ws, err := tfc.resolveWorkspace(ctx.Context, ctx.String("workspace"))
+	if err != nil {
+		return nil, err
+	}
+
+	sv, err := tfc.Client.StateVersions.ReadCurrent(ctx.Context, ws.ID)
+	if err != nil {
+		return nil, fmt.Errorf("reading current state version of %s: %w", ws.Name, err)
+	}
+
+	return sv, nil
+}
+
+func (tfc *TFCClient) StateOutputsCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "outputs",
+		Usage:     "Show the outputs of the current state version of a workspace.",
+		UsageText: "tfc-client state outputs [options] <workspace>",
+		Category:  "state",
+		Action:    tfc.stateOutputs,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "reveal",
+				Usage: "Print the values of sensitive outputs instead of masking them.",
+			},
+		},
+	}
+}
+
+type stateOutputResponse struct {
+	Name      string
+	Type      string
+	Sensitive bool
+	Value     interface{}
+}
+
+func (tfc *TFCClient) stateOutputs(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("expected a workspace name or id")
+	}
+
+	ws, err := tfc.resolveWorkspace(ctx.Context, ctx.Args().First())
+	if err != nil {
+		return err
+	}
+
+	outputs, err := tfc.currentOutputs(ctx, ws.ID, ctx.Bool("reveal"))
+	if err != nil {
+		return err
+	}
+
+	response := make([]stateOutputResponse, len(outputs))
+	for i, o := range outputs {
+		response[i] = stateOutputResponse{
+			Name:      o.Name,
+			Type:      o.Type,
+			Sensitive: o.Sensitive,
+			Value:     o.Value,
+		}
+
+		if o.Sensitive && !ctx.Bool("reveal") {
+			response[i].Value = "<sensitive>"
+		}
+	}
+
+	r, err := json.MarshalIndent(response, "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+	return nil
+}
+
+// currentOutputs reads the outputs of a workspace's current state version. The list endpoint
+// leaves sensitive values out, so when reveal is set they are read one at a time.
+func (tfc *TFCClient) currentOutputs(ctx *cli.Context, workspaceID string, reveal bool) ([]*tfe.StateVersionOutput, error) {
+	ol, err := tfc.Client.StateVersionOutputs.ReadCurrent(ctx.Context, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("reading current outputs of %s: %w", workspaceID, err)
+	}
+
+	if !reveal {
+		return ol.Items, nil
+	}
+
+	for i, o := range ol.Items {
+		if !o.Sensitive {
+			continue
+		}
+
+		full, err := tfc.Client.StateVersionOutputs.Read(ctx.Context, o.ID)
+		if err != nil {
+			return nil, fmt.Errorf("reading sensitive output %s: %w", o.Name, err)
+		}
+		ol.Items[i] = full
+	}
+
+	return ol.Items, nil
+}
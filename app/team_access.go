@@ -0,0 +1,441 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+// TeamAccesses describes all the team access related methods that the Terraform
+// Enterprise API supports.
+//
+// TFE API docs: https://www.terraform.io/docs/cloud/api/team-access.html
+//
+//	// List all the team accesses for a given workspace.
+//	List(ctx context.Context, options *TeamAccessListOptions) (*TeamAccessList, error)
+//
+//	// Add team access for a workspace.
+//	Add(ctx context.Context, options TeamAccessAddOptions) (*TeamAccess, error)
+//
+//	// Update a team access by its ID.
+//	Update(ctx context.Context, teamAccessID string, options TeamAccessUpdateOptions) (*TeamAccess, error)
+//
+//	// Remove team access from a workspace.
+//	Remove(ctx context.Context, teamAccessID string) error
+
+var accessTypes = map[string]tfe.AccessType{
+	"read":   tfe.AccessRead,
+	"plan":   tfe.AccessPlan,
+	"write":  tfe.AccessWrite,
+	"admin":  tfe.AccessAdmin,
+	"custom": tfe.AccessCustom,
+}
+
+func (tfc *TFCClient) AccessListCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "list",
+		Aliases:  []string{"ls"},
+		Usage:    "List the teams with access to a workspace.",
+		Category: "team access",
+		Action:   tfc.accessList,
+		Flags:    []cli.Flag{stateWorkspaceFlag(true)},
+	}
+}
+
+type teamAccessResponse struct {
+	ID               string
+	Team             string
+	TeamID           string
+	Workspace        string `json:",omitempty"`
+	WorkspaceID      string `json:",omitempty"`
+	Access           string
+	Runs             string
+	Variables        string
+	StateVersions    string
+	SentinelMocks    string
+	WorkspaceLocking bool
+	RunTasks         bool
+}
+
+func newTeamAccessResponse(ta *tfe.TeamAccess, teamNames map[string]string) teamAccessResponse {
+	r := teamAccessResponse{
+		ID:               ta.ID,
+		Access:           string(ta.Access),
+		Runs:             string(ta.Runs),
+		Variables:        string(ta.Variables),
+		StateVersions:    string(ta.StateVersions),
+		SentinelMocks:    string(ta.SentinelMocks),
+		WorkspaceLocking: ta.WorkspaceLocking,
+		RunTasks:         ta.RunTasks,
+	}
+
+	if ta.Team != nil {
+		r.TeamID = ta.Team.ID
+		r.Team = teamNames[ta.Team.ID]
+	}
+
+	return r
+}
+
+func (tfc *TFCClient) accessList(ctx *cli.Context) error {
+	ws, err := tfc.resolveWorkspace(ctx.Context, ctx.String("workspace"))
+	if err != nil {
+		return err
+	}
+
+	accesses, err := tfc.listTeamAccess(ctx.Context, ws.ID)
+	if err != nil {
+		return err
+	}
+
+	teamNames, err := tfc.teamNames(ctx.Context)
+	if err != nil {
+		return err
+	}
+
+	response := make([]teamAccessResponse, len(accesses))
+	for i := range accesses {
+		response[i] = newTeamAccessResponse(accesses[i], teamNames)
+		response[i].Workspace, response[i].WorkspaceID = ws.Name, ws.ID
+	}
+
+	sort.Slice(response, func(i, j int) bool { return response[i].Team < response[j].Team })
+
+	r, err := json.MarshalIndent(response, "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+	return nil
+}
+
+func accessTargetFlags() []cli.Flag {
+	return []cli.Flag{
+		teamFlag(),
+		&cli.StringFlag{
+			Name:    "workspace",
+			Aliases: []string{"ws"},
+			Usage:   "name or id of the workspace. One of --workspace or --selector is required.",
+		},
+		selectorFlag(),
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Print the changes without making them.",
+		},
+	}
+}
+
+func (tfc *TFCClient) AccessGrantCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "grant",
+		Usage:    "Grant a team access to a workspace, or to every workspace matching --selector. Existing grants are updated to match.",
+		Category: "team access",
+		Action:   tfc.accessGrant,
+		Flags: append(accessTargetFlags(),
+			&cli.StringFlag{
+				Name:     "access",
+				Aliases:  []string{"a"},
+				Usage:    "(Required) read, plan, write, admin or custom",
+				Required: true,
+			},
+			&cli.StringFlag{Name: "runs", Usage: "custom access: read, plan or apply"},
+			&cli.StringFlag{Name: "variables", Usage: "custom access: none, read or write"},
+			&cli.StringFlag{Name: "state-versions", Usage: "custom access: none, read-outputs, read or write"},
+			&cli.StringFlag{Name: "sentinel-mocks", Usage: "custom access: none or read"},
+			&cli.BoolFlag{Name: "workspace-locking", Usage: "custom access: allow locking and unlocking the workspace"},
+			&cli.BoolFlag{Name: "run-tasks", Usage: "custom access: allow managing run tasks"},
+		),
+	}
+}
+
+// accessGrant holds the permissions requested on the command line. Custom permissions are nil unless passed.
+type accessGrant struct {
+	Access           tfe.AccessType
+	Runs             *tfe.RunsPermissionType
+	Variables        *tfe.VariablesPermissionType
+	StateVersions    *tfe.StateVersionsPermissionType
+	SentinelMocks    *tfe.SentinelMocksPermissionType
+	WorkspaceLocking *bool
+	RunTasks         *bool
+}
+
+func parseAccessGrant(ctx *cli.Context) (*accessGrant, error) {
+	access, ok := accessTypes[ctx.String("access")]
+	if !ok {
+		return nil, fmt.Errorf("access not recognized: %s", ctx.String("access"))
+	}
+
+	g := &accessGrant{
+		Access:           access,
+		WorkspaceLocking: getIfSetBool(ctx, "workspace-locking"),
+		RunTasks:         getIfSetBool(ctx, "run-tasks"),
+	}
+
+	oneOf := func(flag string, allowed ...string) (*string, error) {
+		if !ctx.IsSet(flag) {
+			return nil, nil
+		}
+		v := ctx.String(flag)
+		for _, a := range allowed {
+			if v == a {
+				return &v, nil
+			}
+		}
+		return nil, fmt.Errorf("--%s must be one of %v", flag, allowed)
+	}
+
+	if v, err := oneOf("runs", "read", "plan", "apply"); err != nil {
+		return nil, err
+	} else if v != nil {
+		p := tfe.RunsPermissionType(*v)
+		g.Runs = &p
+	}
+
+	if v, err := oneOf("variables", "none", "read", "write"); err != nil {
+		return nil, err
+	} else if v != nil {
+		p := tfe.VariablesPermissionType(*v)
+		g.Variables = &p
+	}
+
+	if v, err := oneOf("state-versions", "none", "read-outputs", "read", "write"); err != nil {
+		return nil, err
+	} else if v != nil {
+		p := tfe.StateVersionsPermissionType(*v)
+		g.StateVersions = &p
+	}
+
+	if v, err := oneOf("sentinel-mocks", "none", "read"); err != nil {
+		return nil, err
+	} else if v != nil {
+		p := tfe.SentinelMocksPermissionType(*v)
+		g.SentinelMocks = &p
+	}
+
+	custom := g.Runs != nil || g.Variables != nil || g.StateVersions != nil || g.SentinelMocks != nil ||
+		g.WorkspaceLocking != nil || g.RunTasks != nil
+	if custom && access != tfe.AccessCustom {
+		return nil, fmt.Errorf("custom permission flags require --access custom")
+	}
+
+	return g, nil
+}
+
+// satisfiedBy reports whether an existing grant already has exactly the requested permissions.
+func (g *accessGrant) satisfiedBy(ta *tfe.TeamAccess) bool {
+	if ta.Access != g.Access {
+		return false
+	}
+
+	return (g.Runs == nil || *g.Runs == ta.Runs) &&
+		(g.Variables == nil || *g.Variables == ta.Variables) &&
+		(g.StateVersions == nil || *g.StateVersions == ta.StateVersions) &&
+		(g.SentinelMocks == nil || *g.SentinelMocks == ta.SentinelMocks) &&
+		(g.WorkspaceLocking == nil || *g.WorkspaceLocking == ta.WorkspaceLocking) &&
+		(g.RunTasks == nil || *g.RunTasks == ta.RunTasks)
+}
+
+func (tfc *TFCClient) accessGrant(ctx *cli.Context) error {
+	g, err := parseAccessGrant(ctx)
+	if err != nil {
+		return err
+	}
+
+	team, workspaces, err := tfc.accessTargets(ctx)
+	if err != nil {
+		return err
+	}
+
+	dryRun := ctx.Bool("dry-run")
+	var granted, updated, unchanged int
+
+	for _, ws := range workspaces {
+		existing, err := tfc.teamAccessFor(ctx.Context, ws.ID, team.ID)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case existing == nil:
+			if !dryRun {
+				if _, err := tfc.Client.TeamAccess.Add(ctx.Context, tfe.TeamAccessAddOptions{
+					Access:           &g.Access,
+					Runs:             g.Runs,
+					Variables:        g.Variables,
+					StateVersions:    g.StateVersions,
+					SentinelMocks:    g.SentinelMocks,
+					WorkspaceLocking: g.WorkspaceLocking,
+					RunTasks:         g.RunTasks,
+					Team:             team,
+					Workspace:        ws,
+				}); err != nil {
+					return fmt.Errorf("granting %s access on %s: %w", team.Name, ws.Name, err)
+				}
+			}
+			fmt.Printf("granted %s %s access on %s\n", team.Name, g.Access, ws.Name)
+			granted++
+		case g.satisfiedBy(existing):
+			fmt.Printf("unchanged %s %s access on %s\n", team.Name, existing.Access, ws.Name)
+			unchanged++
+		default:
+			if !dryRun {
+				if _, err := tfc.Client.TeamAccess.Update(ctx.Context, existing.ID, tfe.TeamAccessUpdateOptions{
+					Access:           &g.Access,
+					Runs:             g.Runs,
+					Variables:        g.Variables,
+					StateVersions:    g.StateVersions,
+					SentinelMocks:    g.SentinelMocks,
+					WorkspaceLocking: g.WorkspaceLocking,
+					RunTasks:         g.RunTasks,
+				}); err != nil {
+					return fmt.Errorf("updating %s access on %s: %w", team.Name, ws.Name, err)
+				}
+			}
+			fmt.Printf("updated %s access on %s from %s to %s\n", team.Name, ws.Name, existing.Access, g.Access)
+			updated++
+		}
+	}
+
+	if dryRun {
+		fmt.Print("(dry run) ")
+	}
+	fmt.Printf("%d granted, %d updated, %d unchanged\n", granted, updated, unchanged)
+	return nil
+}
+
+func (tfc *TFCClient) AccessRevokeCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "revoke",
+		Usage:    "Revoke a team's access to a workspace, or to every workspace matching --selector.",
+		Category: "team access",
+		Action:   tfc.accessRevoke,
+		Flags:    accessTargetFlags(),
+	}
+}
+
+func (tfc *TFCClient) accessRevoke(ctx *cli.Context) error {
+	team, workspaces, err := tfc.accessTargets(ctx)
+	if err != nil {
+		return err
+	}
+
+	dryRun := ctx.Bool("dry-run")
+	var revoked int
+
+	for _, ws := range workspaces {
+		existing, err := tfc.teamAccessFor(ctx.Context, ws.ID, team.ID)
+		if err != nil {
+			return err
+		}
+
+		if existing == nil {
+			logf(ctx, "%s has no access on %s", team.Name, ws.Name)
+			continue
+		}
+
+		if !dryRun {
+			if err := tfc.Client.TeamAccess.Remove(ctx.Context, existing.ID); err != nil {
+				return fmt.Errorf("revoking %s access on %s: %w", team.Name, ws.Name, err)
+			}
+		}
+
+		fmt.Printf("revoked %s %s access on %s\n", team.Name, existing.Access, ws.Name)
+		revoked++
+	}
+
+	if dryRun {
+		fmt.Print("(dry run) ")
+	}
+	fmt.Printf("%d revoked\n", revoked)
+	return nil
+}
+
+// accessTargets resolves --team and either --workspace or the workspaces matching --selector.
+func (tfc *TFCClient) accessTargets(ctx *cli.Context) (*tfe.Team, []*tfe.Workspace, error) {
+	if ctx.IsSet("workspace") == ctx.IsSet("selector") {
+		return nil, nil, fmt.Errorf("exactly one of --workspace or --selector is required")
+	}
+
+	team, err := tfc.resolveTeam(ctx.Context, ctx.String("team"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ctx.IsSet("workspace") {
+		ws, err := tfc.resolveWorkspace(ctx.Context, ctx.String("workspace"))
+		if err != nil {
+			return nil, nil, err
+		}
+		return team, []*tfe.Workspace{ws}, nil
+	}
+
+	workspaces, err := tfc.selectWorkspaces(ctx.Context, ctx.StringSlice("selector"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(workspaces) == 0 {
+		return nil, nil, fmt.Errorf("no workspaces match the selector")
+	}
+
+	return team, workspaces, nil
+}
+
+// teamAccessFor returns the team's grant on a workspace, or nil if it has none.
+func (tfc *TFCClient) teamAccessFor(ctx context.Context, workspaceID, teamID string) (*tfe.TeamAccess, error) {
+	accesses, err := tfc.listTeamAccess(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ta := range accesses {
+		if ta.Team != nil && ta.Team.ID == teamID {
+			return ta, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// listTeamAccess returns every team access grant on a workspace, walking all pages.
+func (tfc *TFCClient) listTeamAccess(ctx context.Context, workspaceID string) ([]*tfe.TeamAccess, error) {
+	opts := &tfe.TeamAccessListOptions{
+		ListOptions: tfe.ListOptions{PageSize: 100},
+		WorkspaceID: workspaceID,
+	}
+
+	var all []*tfe.TeamAccess
+	for {
+		tal, err := tfc.Client.TeamAccess.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, tal.Items...)
+
+		if tal.Pagination == nil || tal.CurrentPage >= tal.TotalPages {
+			return all, nil
+		}
+		opts.PageNumber = tal.NextPage
+	}
+}
+
+// teamNames maps the organization's team ids to names, since team accesses only carry the id.
+func (tfc *TFCClient) teamNames(ctx context.Context) (map[string]string, error) {
+	teams, err := tfc.listTeams(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(teams))
+	for _, t := range teams {
+		names[t.ID] = t.Name
+	}
+
+	return names, nil
+}
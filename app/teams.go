@@ -0,0 +1,566 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+// Teams describes all the team related methods that the Terraform
+// Enterprise API supports.
+//
+// TFE API docs: https://www.terraform.io/docs/cloud/api/teams.html
+//
+//	// List all the teams of the given organization.
+//	List(ctx context.Context, organization string, options *TeamListOptions) (*TeamList, error)
+//
+//	// Create a new team with the given options.
+//	Create(ctx context.Context, organization string, options TeamCreateOptions) (*Team, error)
+//
+//	// Read a team by its ID.
+//	Read(ctx context.Context, teamID string) (*Team, error)
+//
+//	// Update a team by its ID.
+//	Update(ctx context.Context, teamID string, options TeamUpdateOptions) (*Team, error)
+//
+//	// Delete a team by its ID.
+//	Delete(ctx context.Context, teamID string) error
+//
+// TeamMembers describes all the team member related methods.
+//
+//	// ListUsers returns the Users of this team.
+//	ListUsers(ctx context.Context, teamID string) ([]*User, error)
+//
+//	// ListOrganizationMemberships returns the OrganizationMemberships of this team.
+//	ListOrganizationMemberships(ctx context.Context, teamID string) ([]*OrganizationMembership, error)
+//
+//	// Add multiple users to a team.
+//	Add(ctx context.Context, teamID string, options TeamMemberAddOptions) error
+//
+//	// Remove multiple users from a team.
+//	Remove(ctx context.Context, teamID string, options TeamMemberRemoveOptions) error
+
+func teamFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:     "team",
+		Aliases:  []string{"t"},
+		Usage:    "(Required) name or id of the team.",
+		Required: true,
+	}
+}
+
+// teamSettingsFlags are shared by create and update. Only the flags that are passed are sent.
+func teamSettingsFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "visibility",
+			Usage: "secret or organization",
+		},
+		&cli.StringFlag{
+			Name:  "sso-team-id",
+			Usage: "Unique identifier to control team membership via SAML.",
+		},
+		&cli.BoolFlag{Name: "manage-policies", Usage: "Allow the team to manage policies."},
+		&cli.BoolFlag{Name: "manage-policy-overrides", Usage: "Allow the team to override policy checks."},
+		&cli.BoolFlag{Name: "manage-workspaces", Usage: "Allow the team to manage all workspaces."},
+		&cli.BoolFlag{Name: "manage-vcs-settings", Usage: "Allow the team to manage VCS settings."},
+		&cli.BoolFlag{Name: "manage-providers", Usage: "Allow the team to manage private providers."},
+		&cli.BoolFlag{Name: "manage-modules", Usage: "Allow the team to manage private modules."},
+		&cli.BoolFlag{Name: "manage-run-tasks", Usage: "Allow the team to manage run tasks."},
+	}
+}
+
+func (tfc *TFCClient) TeamsListCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "list",
+		Aliases:  []string{"ls"},
+		Usage:    "List all the teams of the organization.",
+		Category: "teams",
+		Action:   tfc.teamsList,
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "name",
+				Usage: "Only list teams with these names.",
+			},
+		},
+	}
+}
+
+type teamResponse struct {
+	ID                 string
+	Name               string
+	Visibility         string
+	UserCount          int
+	SSOTeamID          string `json:",omitempty"`
+	OrganizationAccess *tfe.OrganizationAccess
+	Members            []teamMemberResponse `json:",omitempty"`
+}
+
+func newTeamResponse(t *tfe.Team) teamResponse {
+	return teamResponse{
+		ID:                 t.ID,
+		Name:               t.Name,
+		Visibility:         t.Visibility,
+		UserCount:          t.UserCount,
+		SSOTeamID:          t.SSOTeamID,
+		OrganizationAccess: t.OrganizationAccess,
+	}
+}
+
+func (tfc *TFCClient) teamsList(ctx *cli.Context) error {
+	teams, err := tfc.listTeams(ctx.Context, ctx.StringSlice("name"))
+	if err != nil {
+		return err
+	}
+
+	response := make([]teamResponse, len(teams))
+	for i := range teams {
+		response[i] = newTeamResponse(teams[i])
+	}
+
+	r, err := json.MarshalIndent(response, "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+	return nil
+}
+
+func (tfc *TFCClient) TeamsShowCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "show",
+		Usage:     "Show a team and its members.",
+		UsageText: "tfc-client teams show <team>",
+		Category:  "teams",
+		Action:    tfc.teamsShow,
+	}
+}
+
+func (tfc *TFCClient) teamsShow(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("expected a team name or id")
+	}
+
+	team, err := tfc.resolveTeam(ctx.Context, ctx.Args().First())
+	if err != nil {
+		return err
+	}
+
+	response := newTeamResponse(team)
+	if response.Members, err = tfc.teamMembers(ctx.Context, team.ID); err != nil {
+		return err
+	}
+
+	r, err := json.MarshalIndent(response, "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+	return nil
+}
+
+func (tfc *TFCClient) TeamsCreateCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "create",
+		Usage:    "Create a team.",
+		Category: "teams",
+		Action:   tfc.teamsCreate,
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:     "name",
+				Aliases:  []string{"n"},
+				Usage:    "(Required) name of the team.",
+				Required: true,
+			},
+		}, teamSettingsFlags()...),
+	}
+}
+
+func (tfc *TFCClient) teamsCreate(ctx *cli.Context) error {
+	team, err := tfc.Client.Teams.Create(ctx.Context, tfc.Cfg.OrgName, tfe.TeamCreateOptions{
+		Name:               ptrString(ctx.String("name")),
+		SSOTeamID:          getIfSetString(ctx, "sso-team-id"),
+		OrganizationAccess: organizationAccessOptions(ctx),
+		Visibility:         getIfSetString(ctx, "visibility"),
+	})
+	if err != nil {
+		return err
+	}
+
+	r, err := json.MarshalIndent(newTeamResponse(team), "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Printf("created team:\n%s\n", string(r))
+	return nil
+}
+
+func (tfc *TFCClient) TeamsUpdateCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "update",
+		Usage:     "Update a team's name, visibility or organization access.",
+		UsageText: "tfc-client teams update [options] <team>",
+		Category:  "teams",
+		Action:    tfc.teamsUpdate,
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:    "name",
+				Aliases: []string{"n"},
+				Usage:   "new name of the team.",
+			},
+		}, teamSettingsFlags()...),
+	}
+}
+
+func (tfc *TFCClient) teamsUpdate(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("expected a team name or id")
+	}
+
+	team, err := tfc.resolveTeam(ctx.Context, ctx.Args().First())
+	if err != nil {
+		return err
+	}
+
+	team, err = tfc.Client.Teams.Update(ctx.Context, team.ID, tfe.TeamUpdateOptions{
+		Name:               getIfSetString(ctx, "name"),
+		SSOTeamID:          getIfSetString(ctx, "sso-team-id"),
+		OrganizationAccess: organizationAccessOptions(ctx),
+		Visibility:         getIfSetString(ctx, "visibility"),
+	})
+	if err != nil {
+		return err
+	}
+
+	r, err := json.MarshalIndent(newTeamResponse(team), "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Printf("updated team:\n%s\n", string(r))
+	return nil
+}
+
+func (tfc *TFCClient) TeamsDeleteCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "delete",
+		Aliases:   []string{"rm"},
+		Usage:     "Delete a team.",
+		UsageText: "tfc-client teams delete <team>",
+		Category:  "teams",
+		Action:    tfc.teamsDelete,
+	}
+}
+
+func (tfc *TFCClient) teamsDelete(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("expected a team name or id")
+	}
+
+	team, err := tfc.resolveTeam(ctx.Context, ctx.Args().First())
+	if err != nil {
+		return err
+	}
+
+	if err := tfc.Client.Teams.Delete(ctx.Context, team.ID); err != nil {
+		return err
+	}
+
+	fmt.Printf("deleted team: %s (%s)\n", team.Name, team.ID)
+	return nil
+}
+
+func (tfc *TFCClient) TeamMembersCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "members",
+		Usage:    "List, add and remove team members.",
+		Category: "teams",
+		Subcommands: []*cli.Command{
+			{
+				Name:    "list",
+				Aliases: []string{"ls"},
+				Usage:   "List the members of a team.",
+				Action:  tfc.teamMembersList,
+				Flags:   []cli.Flag{teamFlag()},
+			},
+			{
+				Name:   "add",
+				Usage:  "Add users to a team by username or organization membership id.",
+				Action: tfc.teamMembersAdd,
+				Flags:  teamMemberFlags(),
+			},
+			{
+				Name:    "remove",
+				Aliases: []string{"rm"},
+				Usage:   "Remove users from a team by username or organization membership id.",
+				Action:  tfc.teamMembersRemove,
+				Flags:   teamMemberFlags(),
+			},
+		},
+	}
+}
+
+func teamMemberFlags() []cli.Flag {
+	return []cli.Flag{
+		teamFlag(),
+		&cli.StringSliceFlag{
+			Name:    "user",
+			Aliases: []string{"u"},
+			Usage:   "username, or organization membership id (ou-...). May be repeated.",
+		},
+		&cli.StringFlag{
+			Name:    "file",
+			Aliases: []string{"f"},
+			Usage:   "File with one username or organization membership id per line, or \"-\" for stdin. Blank lines and lines starting with # are ignored.",
+		},
+	}
+}
+
+type teamMemberResponse struct {
+	UserID                   string `json:",omitempty"`
+	Username                 string `json:",omitempty"`
+	Email                    string `json:",omitempty"`
+	OrganizationMembershipID string `json:",omitempty"`
+	Status                   string `json:",omitempty"`
+}
+
+func (tfc *TFCClient) teamMembersList(ctx *cli.Context) error {
+	team, err := tfc.resolveTeam(ctx.Context, ctx.String("team"))
+	if err != nil {
+		return err
+	}
+
+	members, err := tfc.teamMembers(ctx.Context, team.ID)
+	if err != nil {
+		return err
+	}
+
+	r, err := json.MarshalIndent(members, "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+	return nil
+}
+
+func (tfc *TFCClient) teamMembersAdd(ctx *cli.Context) error {
+	team, usernames, membershipIDs, err := tfc.teamMemberArgs(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(usernames) > 0 {
+		if err := tfc.Client.TeamMembers.Add(ctx.Context, team.ID, tfe.TeamMemberAddOptions{Usernames: usernames}); err != nil {
+			return err
+		}
+	}
+
+	if len(membershipIDs) > 0 {
+		if err := tfc.Client.TeamMembers.Add(ctx.Context, team.ID, tfe.TeamMemberAddOptions{OrganizationMembershipIDs: membershipIDs}); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("added %d members to team %s\n", len(usernames)+len(membershipIDs), team.Name)
+	return nil
+}
+
+func (tfc *TFCClient) teamMembersRemove(ctx *cli.Context) error {
+	team, usernames, membershipIDs, err := tfc.teamMemberArgs(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(usernames) > 0 {
+		if err := tfc.Client.TeamMembers.Remove(ctx.Context, team.ID, tfe.TeamMemberRemoveOptions{Usernames: usernames}); err != nil {
+			return err
+		}
+	}
+
+	if len(membershipIDs) > 0 {
+		if err := tfc.Client.TeamMembers.Remove(ctx.Context, team.ID, tfe.TeamMemberRemoveOptions{OrganizationMembershipIDs: membershipIDs}); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("removed %d members from team %s\n", len(usernames)+len(membershipIDs), team.Name)
+	return nil
+}
+
+// teamMemberArgs resolves --team and splits --user and --file entries into usernames and
+// organization membership ids, which the API takes in separate requests.
+func (tfc *TFCClient) teamMemberArgs(ctx *cli.Context) (*tfe.Team, []string, []string, error) {
+	entries := ctx.StringSlice("user")
+
+	if ctx.IsSet("file") {
+		lines, err := readLines(ctx.String("file"))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		entries = append(entries, lines...)
+	}
+
+	if len(entries) == 0 {
+		return nil, nil, nil, fmt.Errorf("one of --user or --file is required")
+	}
+
+	// A username can look like a membership ID, so it only counts as one when the membership exists
+	var usernames, membershipIDs []string
+	for _, e := range entries {
+		if looksLikeID("ou-", e) {
+			_, err := tfc.Client.OrganizationMemberships.Read(ctx.Context, e)
+			if err == nil {
+				membershipIDs = append(membershipIDs, e)
+				continue
+			}
+			if !errors.Is(err, tfe.ErrResourceNotFound) {
+				return nil, nil, nil, fmt.Errorf("reading organization membership %s: %w", e, err)
+			}
+		}
+		usernames = append(usernames, e)
+	}
+
+	team, err := tfc.resolveTeam(ctx.Context, ctx.String("team"))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return team, usernames, membershipIDs, nil
+}
+
+// teamMembers joins a team's users with their organization memberships.
+func (tfc *TFCClient) teamMembers(ctx context.Context, teamID string) ([]teamMemberResponse, error) {
+	users, err := tfc.Client.TeamMembers.ListUsers(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	memberships, err := tfc.Client.TeamMembers.ListOrganizationMemberships(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	byUser := map[string]*tfe.OrganizationMembership{}
+	for _, m := range memberships {
+		if m.User != nil {
+			byUser[m.User.ID] = m
+		}
+	}
+
+	members := make([]teamMemberResponse, 0, len(users))
+	for _, u := range users {
+		r := teamMemberResponse{
+			UserID:   u.ID,
+			Username: u.Username,
+			Email:    u.Email,
+		}
+
+		if m, ok := byUser[u.ID]; ok {
+			r.OrganizationMembershipID = m.ID
+			r.Status = string(m.Status)
+			if r.Email == "" {
+				r.Email = m.Email
+			}
+			delete(byUser, u.ID)
+		}
+
+		members = append(members, r)
+	}
+
+	// Invited members don't have a user yet
+	for _, m := range memberships {
+		if m.User == nil {
+			members = append(members, teamMemberResponse{
+				Email:                    m.Email,
+				OrganizationMembershipID: m.ID,
+				Status:                   string(m.Status),
+			})
+		}
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Username+members[i].Email < members[j].Username+members[j].Email
+	})
+
+	return members, nil
+}
+
+// listTeams returns every team in the organization, optionally filtered by name, walking all pages.
+func (tfc *TFCClient) listTeams(ctx context.Context, names []string) ([]*tfe.Team, error) {
+	opts := &tfe.TeamListOptions{
+		ListOptions: tfe.ListOptions{PageSize: 100},
+		Names:       names,
+	}
+
+	var all []*tfe.Team
+	for {
+		tl, err := tfc.Client.Teams.List(ctx, tfc.Cfg.OrgName, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, tl.Items...)
+
+		if tl.Pagination == nil || tl.CurrentPage >= tl.TotalPages {
+			return all, nil
+		}
+		opts.PageNumber = tl.NextPage
+	}
+}
+
+// resolveTeam reads a team by ID when given one ("team-" and 16 characters), otherwise by its
+// exact name. A name that happens to look like an ID is looked up by name when no team has that ID.
+func (tfc *TFCClient) resolveTeam(ctx context.Context, nameOrID string) (*tfe.Team, error) {
+	if nameOrID == "" {
+		return nil, fmt.Errorf("a team name or id is required")
+	}
+
+	if looksLikeID("team-", nameOrID) {
+		team, err := tfc.Client.Teams.Read(ctx, nameOrID)
+		if err == nil {
+			return team, nil
+		}
+		if !errors.Is(err, tfe.ErrResourceNotFound) {
+			return nil, fmt.Errorf("reading team %s: %w", nameOrID, err)
+		}
+	}
+
+	teams, err := tfc.listTeams(ctx, []string{nameOrID})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range teams {
+		if t.Name == nameOrID {
+			return t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("team not found: %s", nameOrID)
+}
+
+func organizationAccessOptions(ctx *cli.Context) *tfe.OrganizationAccessOptions {
+	opts := &tfe.OrganizationAccessOptions{
+		ManagePolicies:        getIfSetBool(ctx, "manage-policies"),
+		ManagePolicyOverrides: getIfSetBool(ctx, "manage-policy-overrides"),
+		ManageWorkspaces:      getIfSetBool(ctx, "manage-workspaces"),
+		ManageVCSSettings:     getIfSetBool(ctx, "manage-vcs-settings"),
+		ManageProviders:       getIfSetBool(ctx, "manage-providers"),
+		ManageModules:         getIfSetBool(ctx, "manage-modules"),
+		ManageRunTasks:        getIfSetBool(ctx, "manage-run-tasks"),
+	}
+
+	if *opts == (tfe.OrganizationAccessOptions{}) {
+		return nil
+	}
+	return opts
+}
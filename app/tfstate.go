@@ -0,0 +1,223 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tfState is the subset of the Terraform state v4 JSON format tfc-cli reads.
+// https://developer.hashicorp.com/terraform/internals/json-format
+type tfState struct {
+	Version          int                      `json:"version"`
+	TerraformVersion string                   `json:"terraform_version"`
+	Serial           int64                    `json:"serial"`
+	Lineage          string                   `json:"lineage"`
+	Outputs          map[string]tfStateOutput `json:"outputs"`
+	Resources        []tfStateResource        `json:"resources"`
+}
+
+type tfStateOutput struct {
+	Value     interface{}     `json:"value"`
+	Type      json.RawMessage `json:"type"`
+	Sensitive bool            `json:"sensitive,omitempty"`
+}
+
+type tfStateResource struct {
+	Module    string            `json:"module,omitempty"`
+	Mode      string            `json:"mode"`
+	Type      string            `json:"type"`
+	Name      string            `json:"name"`
+	Provider  string            `json:"provider"`
+	Instances []tfStateInstance `json:"instances"`
+}
+
+type tfStateInstance struct {
+	IndexKey            interface{}              `json:"index_key,omitempty"`
+	SchemaVersion       int                      `json:"schema_version"`
+	Attributes          map[string]interface{}   `json:"attributes"`
+	SensitiveAttributes [][]tfStateAttributeStep `json:"sensitive_attributes,omitempty"`
+	Dependencies        []string                 `json:"dependencies,omitempty"`
+}
+
+// tfStateAttributeStep is one step of a path into an instance's attributes.
+type tfStateAttributeStep struct {
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+func parseState(b []byte) (*tfState, error) {
+	var s tfState
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("invalid state: %w", err)
+	}
+
+	if s.Version != 4 {
+		return nil, fmt.Errorf("unsupported state format version %d, only version 4 is supported", s.Version)
+	}
+
+	return &s, nil
+}
+
+// tfStateEntry is a single resource instance with its address resolved.
+type tfStateEntry struct {
+	Address  string
+	Module   string
+	Mode     string
+	Type     string
+	Name     string
+	Provider string
+
+	instance *tfStateInstance
+}
+
+// entries flattens the state's resources into one entry per instance.
+func (s *tfState) entries() []*tfStateEntry {
+	var entries []*tfStateEntry
+
+	for i := range s.Resources {
+		r := &s.Resources[i]
+
+		base := r.Type + "." + r.Name
+		if r.Mode == "data" {
+			base = "data." + base
+		}
+		if r.Module != "" {
+			base = r.Module + "." + base
+		}
+
+		for j := range r.Instances {
+			inst := &r.Instances[j]
+			entries = append(entries, &tfStateEntry{
+				Address:  base + indexKeySuffix(inst.IndexKey),
+				Module:   r.Module,
+				Mode:     r.Mode,
+				Type:     r.Type,
+				Name:     r.Name,
+				Provider: providerSource(r.Provider),
+				instance: inst,
+			})
+		}
+	}
+
+	return entries
+}
+
+// attr returns the attribute at a dotted path, e.g. tags.Name or ingress.0.cidr_blocks.
+func (e *tfStateEntry) attr(p string) (interface{}, bool) {
+	var v interface{} = e.instance.Attributes
+
+	for _, step := range strings.Split(p, ".") {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			child, ok := t[step]
+			if !ok {
+				return nil, false
+			}
+			v = child
+		case []interface{}:
+			i, err := strconv.Atoi(step)
+			if err != nil || i < 0 || i >= len(t) {
+				return nil, false
+			}
+			v = t[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return v, true
+}
+
+// sensitiveAttrs returns the top level attribute names with sensitive values.
+func (e *tfStateEntry) sensitiveAttrs() map[string]bool {
+	r := map[string]bool{}
+	for _, p := range e.instance.SensitiveAttributes {
+		if len(p) > 0 && p[0].Type == "get_attr" {
+			if name, ok := p[0].Value.(string); ok {
+				r[name] = true
+			}
+		}
+	}
+	return r
+}
+
+func indexKeySuffix(k interface{}) string {
+	switch t := k.(type) {
+	case nil:
+		return ""
+	case string:
+		return "[" + strconv.Quote(t) + "]"
+	case float64:
+		return "[" + strconv.FormatFloat(t, 'f', -1, 64) + "]"
+	default:
+		return fmt.Sprintf("[%v]", t)
+	}
+}
+
+// providerSource strips the provider["..."] wrapper from a state provider address,
+// leaving e.g. registry.terraform.io/hashicorp/aws or registry.terraform.io/hashicorp/aws.east.
+func providerSource(p string) string {
+	if i := strings.Index(p, "provider[\""); i >= 0 {
+		rest := p[i+len("provider[\""):]
+		if j := strings.Index(rest, "\"]"); j >= 0 {
+			return rest[:j] + rest[j+2:]
+		}
+	}
+	return p
+}
+
+// attrString formats an attribute value for matching and display.
+func attrString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	default:
+		b, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Sprint(t)
+		}
+		return string(b)
+	}
+}
+
+// flatAttrs flattens the instance's attributes into dotted paths, one per leaf value.
+// Empty maps and lists are kept as leaves so they still show up when compared.
+func (e *tfStateEntry) flatAttrs() map[string]interface{} {
+	flat := map[string]interface{}{}
+
+	var walk func(prefix string, v interface{})
+	walk = func(prefix string, v interface{}) {
+		join := func(k string) string {
+			if prefix == "" {
+				return k
+			}
+			return prefix + "." + k
+		}
+
+		switch t := v.(type) {
+		case map[string]interface{}:
+			if len(t) == 0 && prefix != "" {
+				flat[prefix] = t
+			}
+			for k, child := range t {
+				walk(join(k), child)
+			}
+		case []interface{}:
+			if len(t) == 0 {
+				flat[prefix] = t
+			}
+			for i, child := range t {
+				walk(join(strconv.Itoa(i)), child)
+			}
+		default:
+			flat[prefix] = v
+		}
+	}
+
+	walk("", e.instance.Attributes)
+	return flat
+}
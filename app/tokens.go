@@ -0,0 +1,546 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+// OrganizationTokens, TeamTokens and UserTokens describe the API token related methods that the
+// Terraform Enterprise API supports. Organizations and teams have a single token, creating a new
+// one replaces it.
+//
+// TFE API docs: https://www.terraform.io/docs/cloud/api/organization-tokens.html
+//
+//	// Create a new organization token, replacing any existing token.
+//	OrganizationTokens.Create(ctx context.Context, organization string) (*OrganizationToken, error)
+//
+//	// Read an organization token.
+//	OrganizationTokens.Read(ctx context.Context, organization string) (*OrganizationToken, error)
+//
+//	// Delete an organization token.
+//	OrganizationTokens.Delete(ctx context.Context, organization string) error
+//
+//	// Create a new team token, replacing any existing token.
+//	TeamTokens.Create(ctx context.Context, teamID string) (*TeamToken, error)
+//
+//	// Read a team token by its ID.
+//	TeamTokens.Read(ctx context.Context, teamID string) (*TeamToken, error)
+//
+//	// Delete a team token by its ID.
+//	TeamTokens.Delete(ctx context.Context, teamID string) error
+//
+//	// Create a new user token
+//	UserTokens.Create(ctx context.Context, userID string, options UserTokenCreateOptions) (*UserToken, error)
+//
+//	// List all the tokens of the given user ID.
+//	UserTokens.List(ctx context.Context, userID string) (*UserTokenList, error)
+//
+//	// Read a user token by its ID.
+//	UserTokens.Read(ctx context.Context, tokenID string) (*UserToken, error)
+//
+//	// Delete a user token by its ID.
+//	UserTokens.Delete(ctx context.Context, tokenID string) error
+
+type tokenResponse struct {
+	ID          string
+	Description string `json:",omitempty"`
+	CreatedAt   time.Time
+	LastUsedAt  *time.Time `json:",omitempty"`
+	// Token is only returned when the token is created
+	Token string `json:",omitempty"`
+}
+
+func newTokenResponse(id, description, token string, createdAt, lastUsedAt time.Time) tokenResponse {
+	r := tokenResponse{
+		ID:          id,
+		Description: description,
+		CreatedAt:   createdAt,
+		Token:       token,
+	}
+
+	if !lastUsedAt.IsZero() {
+		r.LastUsedAt = &lastUsedAt
+	}
+
+	return r
+}
+
+func printToken(v interface{}) error {
+	r, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+	return nil
+}
+
+func (tfc *TFCClient) TokensOrgCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "org",
+		Usage:    "Create, show and revoke the organization token.",
+		Category: "tokens",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "create",
+				Usage: "Create the organization token, replacing the existing one. The token is only printed once.",
+				Action: func(ctx *cli.Context) error {
+					t, err := tfc.Client.OrganizationTokens.Create(ctx.Context, tfc.Cfg.OrgName)
+					if err != nil {
+						return err
+					}
+					return printToken(newTokenResponse(t.ID, t.Description, t.Token, t.CreatedAt, t.LastUsedAt))
+				},
+			},
+			{
+				Name:  "show",
+				Usage: "Show the organization token's metadata.",
+				Action: func(ctx *cli.Context) error {
+					t, err := tfc.Client.OrganizationTokens.Read(ctx.Context, tfc.Cfg.OrgName)
+					if err != nil {
+						return err
+					}
+					return printToken(newTokenResponse(t.ID, t.Description, "", t.CreatedAt, t.LastUsedAt))
+				},
+			},
+			{
+				Name:  "revoke",
+				Usage: "Delete the organization token.",
+				Action: func(ctx *cli.Context) error {
+					if err := tfc.Client.OrganizationTokens.Delete(ctx.Context, tfc.Cfg.OrgName); err != nil {
+						return err
+					}
+					fmt.Printf("revoked the organization token of %s\n", tfc.Cfg.OrgName)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func (tfc *TFCClient) TokensTeamCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "team",
+		Usage:    "Create, show and revoke a team token.",
+		Category: "tokens",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "create",
+				Usage: "Create the team's token, replacing the existing one. The token is only printed once.",
+				Flags: []cli.Flag{teamFlag()},
+				Action: func(ctx *cli.Context) error {
+					team, err := tfc.resolveTeam(ctx.Context, ctx.String("team"))
+					if err != nil {
+						return err
+					}
+
+					t, err := tfc.Client.TeamTokens.Create(ctx.Context, team.ID)
+					if err != nil {
+						return err
+					}
+					return printToken(newTokenResponse(t.ID, t.Description, t.Token, t.CreatedAt, t.LastUsedAt))
+				},
+			},
+			{
+				Name:  "show",
+				Usage: "Show the team token's metadata.",
+				Flags: []cli.Flag{teamFlag()},
+				Action: func(ctx *cli.Context) error {
+					team, err := tfc.resolveTeam(ctx.Context, ctx.String("team"))
+					if err != nil {
+						return err
+					}
+
+					t, err := tfc.Client.TeamTokens.Read(ctx.Context, team.ID)
+					if err != nil {
+						return err
+					}
+					return printToken(newTokenResponse(t.ID, t.Description, "", t.CreatedAt, t.LastUsedAt))
+				},
+			},
+			{
+				Name:  "revoke",
+				Usage: "Delete the team's token.",
+				Flags: []cli.Flag{teamFlag()},
+				Action: func(ctx *cli.Context) error {
+					team, err := tfc.resolveTeam(ctx.Context, ctx.String("team"))
+					if err != nil {
+						return err
+					}
+
+					if err := tfc.Client.TeamTokens.Delete(ctx.Context, team.ID); err != nil {
+						return err
+					}
+					fmt.Printf("revoked the token of team %s\n", team.Name)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func (tfc *TFCClient) TokensUserCmd() *cli.Command {
+	userFlag := &cli.StringFlag{
+		Name:    "user",
+		Aliases: []string{"u"},
+		Usage:   "id of the user. Defaults to the user of the current token; only a user's own tokens are visible.",
+	}
+
+	idFlag := &cli.StringFlag{
+		Name:     "id",
+		Usage:    "(Required) id of the token (at-...).",
+		Required: true,
+	}
+
+	return &cli.Command{
+		Name:     "user",
+		Usage:    "List, create, show and revoke user tokens.",
+		Category: "tokens",
+		Subcommands: []*cli.Command{
+			{
+				Name:    "list",
+				Aliases: []string{"ls"},
+				Usage:   "List a user's tokens.",
+				Flags:   []cli.Flag{userFlag},
+				Action: func(ctx *cli.Context) error {
+					userID, err := tfc.tokenUserID(ctx)
+					if err != nil {
+						return err
+					}
+
+					tl, err := tfc.Client.UserTokens.List(ctx.Context, userID)
+					if err != nil {
+						return err
+					}
+
+					response := make([]tokenResponse, len(tl.Items))
+					for i, t := range tl.Items {
+						response[i] = newTokenResponse(t.ID, t.Description, "", t.CreatedAt, t.LastUsedAt)
+					}
+					return printToken(response)
+				},
+			},
+			{
+				Name:  "create",
+				Usage: "Create a user token. The token is only printed once.",
+				Flags: []cli.Flag{
+					userFlag,
+					&cli.StringFlag{
+						Name:    "description",
+						Aliases: []string{"d"},
+						Usage:   "description of the token",
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					userID, err := tfc.tokenUserID(ctx)
+					if err != nil {
+						return err
+					}
+
+					t, err := tfc.Client.UserTokens.Create(ctx.Context, userID, tfe.UserTokenCreateOptions{Description: ctx.String("description")})
+					if err != nil {
+						return err
+					}
+					return printToken(newTokenResponse(t.ID, t.Description, t.Token, t.CreatedAt, t.LastUsedAt))
+				},
+			},
+			{
+				Name:  "show",
+				Usage: "Show a user token's metadata.",
+				Flags: []cli.Flag{idFlag},
+				Action: func(ctx *cli.Context) error {
+					t, err := tfc.Client.UserTokens.Read(ctx.Context, ctx.String("id"))
+					if err != nil {
+						return err
+					}
+					return printToken(newTokenResponse(t.ID, t.Description, "", t.CreatedAt, t.LastUsedAt))
+				},
+			},
+			{
+				Name:  "revoke",
+				Usage: "Delete a user token.",
+				Flags: []cli.Flag{idFlag},
+				Action: func(ctx *cli.Context) error {
+					if err := tfc.Client.UserTokens.Delete(ctx.Context, ctx.String("id")); err != nil {
+						return err
+					}
+					fmt.Printf("revoked user token %s\n", ctx.String("id"))
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// tokenUserID returns --user, or the id of the user the client's token belongs to.
+func (tfc *TFCClient) tokenUserID(ctx *cli.Context) (string, error) {
+	if ctx.IsSet("user") {
+		return ctx.String("user"), nil
+	}
+
+	u, err := tfc.Client.Users.ReadCurrent(ctx.Context)
+	if err != nil {
+		return "", fmt.Errorf("reading the current user: %w", err)
+	}
+
+	return u.ID, nil
+}
+
+func (tfc *TFCClient) TokensRotateCmd() *cli.Command {
+	return &cli.Command{
+		Name: "rotate",
+		Usage: "Create a new token, store it in a sensitive variable, check it authenticates, and then delete the old one. " +
+			"Team and organization tokens are replaced as soon as the new one is created, so only user tokens keep working until the new token is verified.",
+		UsageText: "tfc-cli tokens rotate (--team T | --org | --user-token at-...) --store varset:<name>/<key>|ws:<workspace>/<key>",
+		Category:  "tokens",
+		Action:    tfc.tokensRotate,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "team",
+				Aliases: []string{"t"},
+				Usage:   "name or id of the team whose token is rotated",
+			},
+			&cli.BoolFlag{
+				Name:  "org",
+				Usage: "rotate the organization token",
+			},
+			&cli.StringFlag{
+				Name:  "user-token",
+				Usage: "id of the user token to rotate (at-...). The new token gets the same description.",
+			},
+			&cli.StringFlag{
+				Name:     "store",
+				Usage:    "(Required) variable to write the new token to: varset:<name or id>/<key> or ws:<name or id>/<key>",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "category",
+				Usage: "category of the variable when it's created: terraform or env",
+				Value: "env",
+			},
+		},
+	}
+}
+
+func (tfc *TFCClient) tokensRotate(ctx *cli.Context) error {
+	targets := 0
+	for _, f := range []string{"team", "org", "user-token"} {
+		if ctx.IsSet(f) {
+			targets++
+		}
+	}
+	if targets != 1 {
+		return fmt.Errorf("exactly one of --team, --org or --user-token is required")
+	}
+
+	category, err := parseCategory(ctx.String("category"))
+	if err != nil {
+		return err
+	}
+
+	// Resolve where the token goes before creating it, a bad --store must not lose a token
+	store, err := tfc.resolveTokenStore(ctx, ctx.String("store"), category)
+	if err != nil {
+		return err
+	}
+
+	var (
+		token   string
+		cleanup func() error
+	)
+
+	switch {
+	case ctx.IsSet("team"):
+		team, err := tfc.resolveTeam(ctx.Context, ctx.String("team"))
+		if err != nil {
+			return err
+		}
+
+		t, err := tfc.Client.TeamTokens.Create(ctx.Context, team.ID)
+		if err != nil {
+			return fmt.Errorf("creating a token for team %s: %w", team.Name, err)
+		}
+		token = t.Token
+		logf(ctx, "created token %s for team %s, the previous token no longer works", t.ID, team.Name)
+	case ctx.Bool("org"):
+		t, err := tfc.Client.OrganizationTokens.Create(ctx.Context, tfc.Cfg.OrgName)
+		if err != nil {
+			return fmt.Errorf("creating an organization token: %w", err)
+		}
+		token = t.Token
+		logf(ctx, "created organization token %s, the previous token no longer works", t.ID)
+	default:
+		old, err := tfc.Client.UserTokens.Read(ctx.Context, ctx.String("user-token"))
+		if err != nil {
+			return fmt.Errorf("reading user token %s: %w", ctx.String("user-token"), err)
+		}
+
+		userID, err := tfc.tokenUserID(ctx)
+		if err != nil {
+			return err
+		}
+
+		t, err := tfc.Client.UserTokens.Create(ctx.Context, userID, tfe.UserTokenCreateOptions{Description: old.Description})
+		if err != nil {
+			return fmt.Errorf("creating a user token: %w", err)
+		}
+		token = t.Token
+		logf(ctx, "created user token %s", t.ID)
+
+		cleanup = func() error {
+			if err := tfc.Client.UserTokens.Delete(ctx.Context, old.ID); err != nil {
+				return fmt.Errorf("deleting the old user token %s: %w", old.ID, err)
+			}
+			fmt.Printf("revoked the old user token %s\n", old.ID)
+			return nil
+		}
+	}
+
+	if err := store.write(ctx.Context, token); err != nil {
+		// The new token is the only copy, don't lose it
+		fmt.Fprintf(os.Stderr, "new token: %s\n", token)
+		return fmt.Errorf("storing the new token in %s: %w", store, err)
+	}
+	fmt.Printf("stored the new token in %s\n", store)
+
+	if err := tfc.verifyToken(ctx.Context, token); err != nil {
+		// Only user tokens are deleted after verifying, team and organization tokens were replaced on create
+		if cleanup != nil {
+			return fmt.Errorf("the new token doesn't authenticate, the old token was kept: %w", err)
+		}
+		return fmt.Errorf("the new token doesn't authenticate, the old token was already replaced and the new one is in %s: %w", store, err)
+	}
+	fmt.Println("verified the new token")
+
+	if cleanup != nil {
+		return cleanup()
+	}
+	return nil
+}
+
+// verifyToken authenticates a new client with the token by reading the organization, which
+// user, team and organization tokens can all do.
+func (tfc *TFCClient) verifyToken(ctx context.Context, token string) error {
+	cfg := &tfe.Config{Token: token}
+	if tfc.Cfg.TFE != nil {
+		c := *tfc.Cfg.TFE
+		c.Token = token
+		cfg = &c
+	}
+
+	client, err := tfe.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Organizations.Read(ctx, tfc.Cfg.OrgName)
+	return err
+}
+
+// tokenStore is a sensitive variable in a variable set or workspace.
+type tokenStore struct {
+	tfc        *TFCClient
+	key        string
+	category   tfe.CategoryType
+	varSet     *tfe.VariableSet
+	workspace  *tfe.Workspace
+	existingID string
+}
+
+func (s *tokenStore) String() string {
+	if s.varSet != nil {
+		return fmt.Sprintf("variable set %s key %s", s.varSet.Name, s.key)
+	}
+	return fmt.Sprintf("workspace %s key %s", s.workspace.Name, s.key)
+}
+
+func (tfc *TFCClient) resolveTokenStore(ctx *cli.Context, spec string, category tfe.CategoryType) (*tokenStore, error) {
+	kind, rest, ok := strings.Cut(spec, ":")
+	i := strings.LastIndex(rest, "/")
+	if !ok || i <= 0 || i == len(rest)-1 {
+		return nil, fmt.Errorf("--store must look like varset:<name>/<key> or ws:<name>/<key>, got %s", spec)
+	}
+
+	owner, key := rest[:i], rest[i+1:]
+	s := &tokenStore{tfc: tfc, key: key, category: category}
+	c := string(category)
+
+	switch kind {
+	case "varset":
+		vs, err := tfc.resolveVarSetNameOrID(ctx, owner)
+		if err != nil {
+			return nil, err
+		}
+		s.varSet = vs
+
+		v, err := findVarSetVariable(vs, key, &c)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			s.existingID = v.ID
+		}
+	case "ws":
+		ws, err := tfc.resolveWorkspace(ctx.Context, owner)
+		if err != nil {
+			return nil, err
+		}
+		s.workspace = ws
+
+		vars, err := tfc.listWorkspaceVariables(ctx.Context, ws.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vars {
+			if v.Key == key && v.Category == category {
+				s.existingID = v.ID
+			}
+		}
+	default:
+		return nil, fmt.Errorf("--store must start with varset: or ws:, got %s", spec)
+	}
+
+	return s, nil
+}
+
+func (s *tokenStore) write(ctx context.Context, token string) error {
+	client := s.tfc.Client
+
+	switch {
+	case s.varSet != nil && s.existingID != "":
+		_, err := client.VariableSetVariables.Update(ctx, s.varSet.ID, s.existingID, &tfe.VariableSetVariableUpdateOptions{
+			Value:     tfe.String(token),
+			Sensitive: ptrBool(true),
+		})
+		return err
+	case s.varSet != nil:
+		_, err := client.VariableSetVariables.Create(ctx, s.varSet.ID, &tfe.VariableSetVariableCreateOptions{
+			Key:       tfe.String(s.key),
+			Value:     tfe.String(token),
+			Category:  &s.category,
+			Sensitive: ptrBool(true),
+		})
+		return err
+	case s.existingID != "":
+		_, err := client.Variables.Update(ctx, s.workspace.ID, s.existingID, tfe.VariableUpdateOptions{
+			Value:     tfe.String(token),
+			Sensitive: ptrBool(true),
+		})
+		return err
+	default:
+		_, err := client.Variables.Create(ctx, s.workspace.ID, tfe.VariableCreateOptions{
+			Key:       tfe.String(s.key),
+			Value:     tfe.String(token),
+			Category:  &s.category,
+			Sensitive: ptrBool(true),
+		})
+		return err
+	}
+}
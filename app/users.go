@@ -0,0 +1,132 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+// Users describes all the user related methods that the Terraform
+// Enterprise API supports.
+//
+// TFE API docs: https://www.terraform.io/docs/cloud/api/account.html
+//
+//	// ReadCurrent reads the details of the currently authenticated user.
+//	ReadCurrent(ctx context.Context) (*User, error)
+
+func (tfc *TFCClient) WhoamiCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "whoami",
+		Usage:     "Show who the API token authenticates as, and its membership in the organization",
+		UsageText: "Show who the API token authenticates as, and its membership in the organization\nReference: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/account",
+		Action:    tfc.whoami,
+	}
+}
+
+type whoamiResponse struct {
+	Address          string `json:",omitempty"`
+	Organization     string
+	TokenType        string
+	UserID           string `json:",omitempty"`
+	Username         string `json:",omitempty"`
+	Email            string `json:",omitempty"`
+	IsServiceAccount bool
+	TwoFactor        bool
+	Membership       *memberResponse `json:",omitempty"`
+	Note             string          `json:",omitempty"`
+}
+
+func (tfc *TFCClient) whoami(ctx *cli.Context) error {
+	u, err := tfc.Client.Users.ReadCurrent(ctx.Context)
+	if errors.Is(err, tfe.ErrUnauthorized) || errors.Is(err, tfe.ErrResourceNotFound) {
+		// Organization tokens can't read the current user, but can read their organization
+		return tfc.whoamiOrganization(ctx, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	response := whoamiResponse{
+		Organization:     tfc.Cfg.OrgName,
+		TokenType:        tokenType(u),
+		UserID:           u.ID,
+		Username:         u.Username,
+		Email:            u.Email,
+		IsServiceAccount: u.IsServiceAccount,
+		TwoFactor:        u.TwoFactor != nil && u.TwoFactor.Enabled,
+	}
+
+	if tfc.Cfg.TFE != nil {
+		response.Address = tfc.Cfg.TFE.Address
+	}
+
+	// Only user tokens belong to an organization member
+	if !u.IsServiceAccount && u.Email != "" {
+		m, err := tfc.resolveMembership(ctx.Context, u.Email)
+		if err != nil {
+			response.Note = fmt.Sprintf("no membership in %s is visible to this token: %s", tfc.Cfg.OrgName, err)
+		} else {
+			r := newMemberResponse(m)
+			response.Membership = &r
+		}
+	}
+
+	r, err := json.MarshalIndent(response, "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+	return nil
+}
+
+// whoamiOrganization reports an organization token, given the error reading the current user.
+func (tfc *TFCClient) whoamiOrganization(ctx *cli.Context, userErr error) error {
+	if _, err := tfc.Client.Organizations.Read(ctx.Context, tfc.Cfg.OrgName); err != nil {
+		switch {
+		case errors.Is(err, tfe.ErrUnauthorized):
+			return fmt.Errorf("the token was rejected, it is invalid, expired or revoked: %w", userErr)
+		case errors.Is(err, tfe.ErrResourceNotFound):
+			return fmt.Errorf("organization %s not found or not visible to this token: %w", tfc.Cfg.OrgName, err)
+		}
+		return err
+	}
+
+	response := whoamiResponse{
+		Organization:     tfc.Cfg.OrgName,
+		TokenType:        "organization",
+		IsServiceAccount: true,
+		Note:             "organization tokens can't read the current user",
+	}
+
+	if tfc.Cfg.TFE != nil {
+		response.Address = tfc.Cfg.TFE.Address
+	}
+
+	r, err := json.MarshalIndent(response, "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+	return nil
+}
+
+// tokenType guesses the kind of token from the service account the API returns for it:
+// organization tokens authenticate as api-org-..., team tokens as api-team_...
+func tokenType(u *tfe.User) string {
+	switch {
+	case !u.IsServiceAccount:
+		return "user"
+	case strings.HasPrefix(u.Username, "api-org-"):
+		return "organization"
+	case strings.HasPrefix(u.Username, "api-team"):
+		return "team"
+	default:
+		return "service account"
+	}
+}
@@ -3,6 +3,7 @@ package app
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/go-tfe"
 	"github.com/urfave/cli/v2"
@@ -35,9 +36,14 @@ func (tfc *TFCClient) VarSetVariablesListCmd() *cli.Command {
 		Usage:    "List all variables in the variable set.",
 		Category: "variable-set variables",
 		Action: func(ctx *cli.Context) error {
+			varSet, err := tfc.resolveVarSet(ctx, ctx.String("var-set-id"), ctx.String("set-name"), nil)
+			if err != nil {
+				return err
+			}
+
 			vsl, err := tfc.Client.VariableSetVariables.List(
 				ctx.Context,
-				ctx.String("var-set-id"), &tfe.VariableSetVariableListOptions{
+				varSet.ID, &tfe.VariableSetVariableListOptions{
 					ListOptions: tfe.ListOptions{
 						PageNumber: ctx.Int("page-num"),
 						PageSize:   ctx.Int("page-size"),
@@ -58,10 +64,14 @@ func (tfc *TFCClient) VarSetVariablesListCmd() *cli.Command {
 		},
 		Flags: []cli.Flag{
 			&cli.StringFlag{
-				Name:     "var-set-id",
-				Usage:    "id of the variable set to query. See tfc-client var-sets to query variable sets.",
-				Aliases:  []string{"id"},
-				Required: true,
+				Name:    "var-set-id",
+				Usage:   "id of the variable set to query. See tfc-client var-sets to query variable sets.",
+				Aliases: []string{"id"},
+			},
+			&cli.StringFlag{
+				Name:    "set-name",
+				Usage:   "name of the variable set to query. Used when var-set-id isn't passed.",
+				Aliases: []string{"name"},
 			},
 			&cli.IntFlag{
 				Name:  "page-num",
@@ -75,201 +85,416 @@ func (tfc *TFCClient) VarSetVariablesListCmd() *cli.Command {
 	}
 }
 
+// varSetVariableSetFlags select the variable set a variable command operates on.
+func varSetVariableSetFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "set-id",
+			Usage: "id of the variable set containing the variable to modify. See tfc-client var-sets to query variable sets.",
+		},
+		&cli.StringFlag{
+			Name:  "set-name",
+			Usage: "name of the variable set containing the variable to modify. See tfc-client var-sets to query variable sets.",
+		},
+	}
+}
+
+// varSetVariableInputFlags describe one variable on the command line, or many through --json.
+func varSetVariableInputFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "key",
+			Aliases: []string{"k"},
+			Usage:   "key of the variable. Required unless --json is passed.",
+		},
+		&cli.StringFlag{
+			Name:    "value",
+			Usage:   "The value of the variable.",
+			Aliases: []string{"v"},
+		},
+		&cli.StringFlag{
+			Name:  "value-file",
+			Usage: "Read the value of the variable from a file, or from stdin when \"-\". A single trailing newline is dropped.",
+		},
+		&cli.StringFlag{
+			Name:    "description",
+			Usage:   "The description of the variable.",
+			Aliases: []string{"d"},
+		},
+		&cli.StringFlag{
+			Name:    "category",
+			Usage:   "Whether this is a terraform or env variable. Defaults to terraform on create.",
+			Aliases: []string{"c"},
+		},
+		&cli.BoolFlag{
+			Name:  "hcl",
+			Usage: "Whether to evaluate the value of the variable as a string of HCL code.",
+		},
+		&cli.BoolFlag{
+			Name:    "sensitive",
+			Usage:   "Whether the value is sensitive.",
+			Aliases: []string{"s"},
+		},
+		&cli.StringFlag{
+			Name:    "json",
+			Usage:   "Read variables from a JSON file, or from stdin when \"-\". Accepts a single object or a list of objects with key, value, description, category, hcl and sensitive fields.",
+			Aliases: []string{"j"},
+		},
+	}
+}
+
+func (tfc *TFCClient) VarSetVariablesCreateCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "create",
+		Usage:    "Create variable set variables.\none of set-id, set-name is required",
+		Category: "variable-set variables",
+		Action:   tfc.varSetVariableCreate,
+		Flags:    append(varSetVariableSetFlags(), varSetVariableInputFlags()...),
+	}
+}
+
 func (tfc *TFCClient) VarSetVariablesUpdateCmd() *cli.Command {
 	return &cli.Command{
 		Name:     "update",
-		Usage:    "Update variable set variable.\none of set-id, var-id is required",
+		Usage:    "Update variable set variables.\none of set-id, set-name is required",
 		Category: "variable-set variables",
 		Action:   tfc.varSetVariableUpdate,
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:  "set-id",
-				Usage: "id of the variable set containing the variable to modify. See tfc-client var-sets to query variable sets.",
-			},
-			&cli.StringFlag{
-				Name:  "set-name",
-				Usage: "name of the variable set containing the variable to modify. See tfc-client var-sets to query variable sets. IGNORED IF var-set-id IS SET.",
-			},
-			&cli.StringFlag{
+		Flags:    append(varSetVariableSetFlags(), varSetVariableInputFlags()...),
+	}
+}
+
+func (tfc *TFCClient) VarSetVariablesUpsertCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "upsert",
+		Usage:    "Update variable set variables, creating the ones that don't exist.\none of set-id, set-name is required",
+		Category: "variable-set variables",
+		Action:   tfc.varSetVariableUpsert,
+		Flags:    append(varSetVariableSetFlags(), varSetVariableInputFlags()...),
+	}
+}
+
+func (tfc *TFCClient) VarSetVariablesDeleteCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "delete",
+		Aliases:  []string{"rm"},
+		Usage:    "Delete variable set variables by key.\none of set-id, set-name is required",
+		Category: "variable-set variables",
+		Action:   tfc.varSetVariableDelete,
+		Flags: append(varSetVariableSetFlags(),
+			&cli.StringSliceFlag{
 				Name:     "key",
 				Aliases:  []string{"k"},
-				Usage:    "(Required) key of the variable to modify.",
-				Required: true,
-			},
-			&cli.StringFlag{
-				Name:     "value",
-				Usage:    "(Required) The value of the variable.",
-				Aliases:  []string{"v"},
+				Usage:    "(Required) key of the variable to delete. May be repeated.",
 				Required: true,
 			},
 			&cli.StringFlag{
-				Name:    "description",
-				Usage:   "The description of the variable.",
-				Aliases: []string{"d"},
-			},
-			&cli.BoolFlag{
-				Name:  "hcl",
-				Usage: "Whether to evaluate the value of the variable as a string of HCL code.",
-			},
-			&cli.BoolFlag{
-				Name:    "sensitive",
-				Usage:   "Whether the value is sensitive.",
-				Aliases: []string{"s"},
+				Name:    "category",
+				Usage:   "Only delete the variable with this category (terraform or env).",
+				Aliases: []string{"c"},
 			},
-			// TODO: accept json input
-		},
+		),
 	}
 }
 
+// varSetVariableInput is a single variable read from flags or --json input. Unset
+// fields are left untouched on update.
+type varSetVariableInput struct {
+	Key         string  `json:"key"`
+	Value       *string `json:"value,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Category    *string `json:"category,omitempty"`
+	HCL         *bool   `json:"hcl,omitempty"`
+	Sensitive   *bool   `json:"sensitive,omitempty"`
+}
+
+func (tfc *TFCClient) varSetVariableCreate(ctx *cli.Context) error {
+	inputs, err := varSetVariableInputs(ctx)
+	if err != nil {
+		return err
+	}
+
+	varSet, err := tfc.varSetVariableSet(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, in := range inputs {
+		vsv, err := tfc.createVarSetVariable(ctx, varSet, in)
+		if err != nil {
+			return err
+		}
+
+		if err := printVarSetVariable("created", vsv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (tfc *TFCClient) varSetVariableUpdate(ctx *cli.Context) error {
-	if ctx.IsSet("set-id") && ctx.IsSet("set-name") {
-		return fmt.Errorf("only one of \"--var-set-id\" or \"--var-set-name\" can be used")
+	inputs, err := varSetVariableInputs(ctx)
+	if err != nil {
+		return err
 	}
 
-	var (
-		varSet  *tfe.VariableSet
-		varID   *string
-		verbose = ctx.Bool("verbose")
-	)
+	varSet, err := tfc.varSetVariableSet(ctx)
+	if err != nil {
+		return err
+	}
 
-	// Fetch the variable set with all related variables
-	if ctx.IsSet("set-id") {
-		var err error
-		opts := &tfe.VariableSetReadOptions{Include: &[]tfe.VariableSetIncludeOpt{tfe.VariableSetVars}}
+	for _, in := range inputs {
+		v, err := findVarSetVariable(varSet, in.Key, in.Category)
+		if err != nil {
+			return err
+		}
 
-		if verbose {
-			fmt.Printf("recieved set-id: %s\nReading variable set with options: %+v\n", ctx.String("set-id"), *opts)
+		if v == nil {
+			return fmt.Errorf("matching variable not found\nkey: %s", in.Key)
 		}
 
-		if varSet, err = tfc.Client.VariableSets.Read(ctx.Context, ctx.String("set-id"), opts); err != nil {
+		vsv, err := tfc.updateVarSetVariable(ctx, varSet, v, in)
+		if err != nil {
 			return err
 		}
 
-		if verbose {
-			fmt.Printf("successfully read variable set: %+v\n", varSet)
+		if err := printVarSetVariable("updated", vsv); err != nil {
+			return err
 		}
-	} else {
-		// If we get a name and not an ID we need to query for the ID
-		var p *tfe.Pagination
-		for p == nil || p.CurrentPage < p.TotalPages {
-			p = &tfe.Pagination{
-				NextPage: 0,
-			}
-			opts := &tfe.VariableSetListOptions{ListOptions: tfe.ListOptions{
-				PageNumber: p.NextPage,
-				PageSize:   50,
-			}, Include: string(tfe.VariableSetVars)}
+	}
 
-			if verbose {
-				fmt.Printf("listing variable sets with options: %+v\n", opts)
-			}
+	return nil
+}
+
+func (tfc *TFCClient) varSetVariableUpsert(ctx *cli.Context) error {
+	inputs, err := varSetVariableInputs(ctx)
+	if err != nil {
+		return err
+	}
+
+	varSet, err := tfc.varSetVariableSet(ctx)
+	if err != nil {
+		return err
+	}
 
-			lr, err := tfc.Client.VariableSets.List(ctx.Context, tfc.Cfg.OrgName, opts)
+	for _, in := range inputs {
+		v, err := findVarSetVariable(varSet, in.Key, in.Category)
+		if err != nil {
+			return err
+		}
+
+		if v == nil {
+			vsv, err := tfc.createVarSetVariable(ctx, varSet, in)
 			if err != nil {
 				return err
 			}
 
-			fmt.Println("Total variable sets returned: ", lr.TotalCount)
-
-			// look for matching set on this page
-			fmt.Println("looking for matching set: ", ctx.String("set-name"))
-			for _, vs := range lr.Items {
-				if vs.Name == ctx.String("set-name") {
-					if verbose {
-						// OMG why did I write so much logging??
-						var result struct {
-							ID          string                    `json:"id"`
-							Name        string                    `json:"name"`
-							Description string                    `json:"description"`
-							Global      bool                      `json:"global"`
-							Variables   []tfe.VariableSetVariable `json:"vars,omitempty"`
-						}
-
-						result.ID = vs.ID
-						result.Global = vs.Global
-						result.Name = vs.Name
-						result.Description = vs.Description
-						result.Variables = make([]tfe.VariableSetVariable, len(vs.Variables))
-
-						for i := range vs.Variables {
-							result.Variables[i] = *vs.Variables[i]
-						}
-
-						fmt.Printf("Variable set name match: %+v\n", result)
-					}
-					varSet = vs
-					break
-				}
-
-				if verbose {
-					fmt.Printf("Variable set name doesn't match: %s\n", vs.Name)
-				}
+			if err := printVarSetVariable("created", vsv); err != nil {
+				return err
 			}
+			continue
+		}
 
-			// If we found it, stop looking
-			if varSet != nil {
-				break
-			}
+		vsv, err := tfc.updateVarSetVariable(ctx, varSet, v, in)
+		if err != nil {
+			return err
+		}
 
-			p = lr.Pagination
+		if err := printVarSetVariable("updated", vsv); err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
+
+func (tfc *TFCClient) varSetVariableDelete(ctx *cli.Context) error {
+	varSet, err := tfc.varSetVariableSet(ctx)
+	if err != nil {
+		return err
+	}
+
+	category := getIfSetString(ctx, "category")
+
+	// Resolve every key before deleting anything so a typo doesn't leave the set half cleaned up
+	vars := make([]*tfe.VariableSetVariable, 0, len(ctx.StringSlice("key")))
+	for _, key := range ctx.StringSlice("key") {
+		v, err := findVarSetVariable(varSet, key, category)
+		if err != nil {
+			return err
+		}
+
+		if v == nil {
+			return fmt.Errorf("matching variable not found\nkey: %s", key)
+		}
+
+		vars = append(vars, v)
+	}
+
+	for _, v := range vars {
+		if ctx.Bool("verbose") {
+			fmt.Printf("Deleting variable: %s (%s) from variable set %s\n", v.Key, v.ID, varSet.ID)
+		}
+
+		if err := tfc.Client.VariableSetVariables.Delete(ctx.Context, varSet.ID, v.ID); err != nil {
+			return err
+		}
+
+		fmt.Printf("deleted variable set variable: %s\n", v.Key)
+	}
+
+	return nil
+}
 
-		if varSet == nil {
-			return fmt.Errorf("variable set with matching name not found")
+func (tfc *TFCClient) createVarSetVariable(ctx *cli.Context, varSet *tfe.VariableSet, in varSetVariableInput) (*tfe.VariableSetVariable, error) {
+	category := tfe.CategoryTerraform
+	if in.Category != nil {
+		c, err := parseCategory(*in.Category)
+		if err != nil {
+			return nil, err
 		}
+		category = c
 	}
 
+	opts := &tfe.VariableSetVariableCreateOptions{
+		Key:         ptrString(in.Key),
+		Value:       in.Value,
+		Description: in.Description,
+		Category:    &category,
+		HCL:         in.HCL,
+		Sensitive:   in.Sensitive,
+	}
+
+	if ctx.Bool("verbose") {
+		fmt.Printf("Creating variable %s in variable set %s\n", in.Key, varSet.ID)
+	}
+
+	return tfc.Client.VariableSetVariables.Create(ctx.Context, varSet.ID, opts)
+}
+
+func (tfc *TFCClient) updateVarSetVariable(ctx *cli.Context, varSet *tfe.VariableSet, v *tfe.VariableSetVariable, in varSetVariableInput) (*tfe.VariableSetVariable, error) {
+	opts := &tfe.VariableSetVariableUpdateOptions{
+		Key:         ptrString(in.Key),
+		Value:       in.Value,
+		Description: in.Description,
+		HCL:         in.HCL,
+		Sensitive:   in.Sensitive,
+	}
+
+	if ctx.Bool("verbose") {
+		fmt.Printf("Updating variable %s (%s) in variable set %s\n", v.Key, v.ID, varSet.ID)
+	}
+
+	return tfc.Client.VariableSetVariables.Update(ctx.Context, varSet.ID, v.ID, opts)
+}
+
+// findVarSetVariable returns the variable in the set matching key, and category when given.
+// A key that exists in both categories must be disambiguated with a category.
+func findVarSetVariable(varSet *tfe.VariableSet, key string, category *string) (*tfe.VariableSetVariable, error) {
+	var match *tfe.VariableSetVariable
+
 	for _, v := range varSet.Variables {
-		if v.Key == ctx.String("key") {
-			if verbose {
-				fmt.Printf("Variable key match: %+v\n", *v)
+		if v.Key != key {
+			continue
+		}
+
+		if category != nil && string(v.Category) != *category {
+			continue
+		}
+
+		if match != nil {
+			return nil, fmt.Errorf("key %s exists as both a terraform and env variable; pass a category", key)
+		}
+		match = v
+	}
+
+	return match, nil
+}
+
+// varSetVariableInputs reads the variables to operate on from --json, or from the single
+// variable flags when --json isn't passed.
+func varSetVariableInputs(ctx *cli.Context) ([]varSetVariableInput, error) {
+	if ctx.IsSet("json") {
+		if ctx.IsSet("key") || ctx.IsSet("value") || ctx.IsSet("value-file") {
+			return nil, fmt.Errorf("--json can't be combined with --key, --value or --value-file")
+		}
+
+		b, err := readInput(ctx.String("json"))
+		if err != nil {
+			return nil, err
+		}
+
+		var inputs []varSetVariableInput
+		if trimmed := strings.TrimSpace(string(b)); strings.HasPrefix(trimmed, "{") {
+			var in varSetVariableInput
+			if err := json.Unmarshal(b, &in); err != nil {
+				return nil, fmt.Errorf("invalid json input: %w", err)
 			}
-			varID = ptrString(v.ID)
-			if err := ctx.Set("key", v.Key); err != nil {
-				return err
+			inputs = append(inputs, in)
+		} else if err := json.Unmarshal(b, &inputs); err != nil {
+			return nil, fmt.Errorf("invalid json input: %w", err)
+		}
+
+		for i, in := range inputs {
+			if in.Key == "" {
+				return nil, fmt.Errorf("json input %d is missing a key", i)
+			}
+			if in.Category != nil {
+				if _, err := parseCategory(*in.Category); err != nil {
+					return nil, err
+				}
 			}
 		}
-	}
 
-	if varID == nil {
-		return fmt.Errorf("matching variable not found\nkey: %s", ctx.String("key"))
+		return inputs, nil
 	}
 
-	opts := &tfe.VariableSetVariableUpdateOptions{
-		Key:         ptrString(ctx.String("key")),
-		Value:       ptrString(ctx.String("value")),
-		Description: ptrString(ctx.String("description")),
+	if !ctx.IsSet("key") {
+		return nil, fmt.Errorf("one of --key or --json is required")
 	}
 
-	if ctx.IsSet("hcl") {
-		opts.HCL = ptrBool(ctx.Bool("hcl"))
+	if ctx.IsSet("value") && ctx.IsSet("value-file") {
+		return nil, fmt.Errorf("only one of --value or --value-file can be passed")
 	}
 
-	if ctx.IsSet("sensitive") {
-		opts.Sensitive = ptrBool(ctx.Bool("sensitive"))
+	in := varSetVariableInput{
+		Key:         ctx.String("key"),
+		Value:       getIfSetString(ctx, "value"),
+		Description: getIfSetString(ctx, "description"),
+		Category:    getIfSetString(ctx, "category"),
+		HCL:         getIfSetBool(ctx, "hcl"),
+		Sensitive:   getIfSetBool(ctx, "sensitive"),
 	}
 
-	if verbose {
-		fmt.Printf("Updating Variable: %+v\n", struct {
-			variableSetID, variableID string
-			options                   tfe.VariableSetVariableUpdateOptions
-		}{
-			variableSetID: varSet.ID,
-			variableID:    *varID,
-			options:       *opts,
-		})
+	if ctx.IsSet("value-file") {
+		b, err := readInput(ctx.String("value-file"))
+		if err != nil {
+			return nil, err
+		}
+		v := strings.TrimSuffix(strings.TrimSuffix(string(b), "\n"), "\r")
+		in.Value = &v
 	}
 
-	vsv, err := tfc.Client.VariableSetVariables.Update(ctx.Context, varSet.ID, *varID, opts)
-	if err != nil {
-		return err
+	if in.Category != nil {
+		if _, err := parseCategory(*in.Category); err != nil {
+			return nil, err
+		}
 	}
 
+	return []varSetVariableInput{in}, nil
+}
+
+// varSetVariableSet fetches the variable set selected by --set-id or --set-name with all related variables.
+func (tfc *TFCClient) varSetVariableSet(ctx *cli.Context) (*tfe.VariableSet, error) {
+	return tfc.resolveVarSet(ctx, ctx.String("set-id"), ctx.String("set-name"), []tfe.VariableSetIncludeOpt{tfe.VariableSetVars})
+}
+
+func printVarSetVariable(action string, vsv *tfe.VariableSetVariable) error {
 	r, err := json.MarshalIndent(vsv, "", "    ")
 	if err != nil {
-		return nil
+		return err
 	}
 
-	fmt.Printf("updated variable set variable:\n%s\n", string(r))
+	fmt.Printf("%s variable set variable:\n%s\n", action, string(r))
 	return nil
 }
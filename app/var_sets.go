@@ -1,7 +1,9 @@
 package app
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/hashicorp/go-tfe"
 	"github.com/urfave/cli/v2"
@@ -74,42 +76,41 @@ func (tfc *TFCClient) VarSetsListCmd() *cli.Command {
 	}
 }
 func (tfc *TFCClient) varSetsList(ctx *cli.Context) error {
-	opts := &tfe.VariableSetListOptions{
-		ListOptions: tfe.ListOptions{
-			PageNumber: ctx.Int("page-num"),
-			PageSize:   ctx.Int("page-size"),
-		},
-	}
-
-	include := ctx.StringSlice("include")
-
-	for _, r := range include {
-		if opt, ok := varSetIncludeOpts[r]; !ok {
-			return fmt.Errorf("include opt not recognized: %s", r)
-		} else {
-			if opts.Include == "" {
-				opts.Include = string(opt)
-				continue
-			}
-			opts.Include = strings.Join([]string{opts.Include, string(opt)}, ",")
-		}
-	}
-
-	vsl, err := tfc.Client.VariableSets.List(ctx.Context, tfc.Cfg.OrgName, opts)
+	include, err := parseVarSetIncludes(ctx.StringSlice("include"))
 	if err != nil {
 		return err
 	}
 
-	r := vsl.Items
+	var r []*tfe.VariableSet
 
+	// A name search has to look at every page, otherwise only the requested page is returned
 	if ctx.IsSet("search") {
-		r := []*tfe.VariableSet{}
+		all, err := tfc.listVarSets(ctx.Context, include)
+		if err != nil {
+			return err
+		}
 
-		for _, vs := range vsl.Items {
+		r = []*tfe.VariableSet{}
+		for _, vs := range all {
 			if vs.Name == ctx.String("search") {
 				r = append(r, vs)
 			}
 		}
+	} else {
+		opts := &tfe.VariableSetListOptions{
+			ListOptions: tfe.ListOptions{
+				PageNumber: ctx.Int("page-num"),
+				PageSize:   ctx.Int("page-size"),
+			},
+			Include: varSetIncludeString(include),
+		}
+
+		vsl, err := tfc.Client.VariableSets.List(ctx.Context, tfc.Cfg.OrgName, opts)
+		if err != nil {
+			return err
+		}
+
+		r = vsl.Items
 	}
 
 	pp, err := json.MarshalIndent(r, "", "    ")
@@ -163,25 +164,17 @@ type varSetResponse struct {
 }
 
 func (tfc *TFCClient) varSetsListForWorkspace(ctx *cli.Context) error {
+	include, err := parseVarSetIncludes(ctx.StringSlice("include"))
+	if err != nil {
+		return err
+	}
+
 	opts := &tfe.VariableSetListOptions{
 		ListOptions: tfe.ListOptions{
 			PageNumber: ctx.Int("page-num"),
 			PageSize:   ctx.Int("page-size"),
 		},
-	}
-
-	include := ctx.StringSlice("include")
-
-	for _, r := range include {
-		if opt, ok := varSetIncludeOpts[r]; !ok {
-			return fmt.Errorf("include opt not recognized: %s", r)
-		} else {
-			if opts.Include == "" {
-				opts.Include = string(opt)
-				continue
-			}
-			opts.Include = strings.Join([]string{opts.Include, string(opt)}, ",")
-		}
+		Include: varSetIncludeString(include),
 	}
 
 	vsl, err := tfc.Client.VariableSets.ListForWorkspace(ctx.Context, ctx.String("workspace-id"), opts)
@@ -245,63 +238,149 @@ func (tfc *TFCClient) VarSetsReadCmd() *cli.Command {
 	}
 }
 func (tfc *TFCClient) varSetsRead(ctx *cli.Context) error {
-	if !ctx.IsSet("id") && !ctx.IsSet("name") {
-		return fmt.Errorf("one of --id or --name is required")
+	include, err := parseVarSetIncludes(ctx.StringSlice("include"))
+	if err != nil {
+		return err
 	}
 
-	if ctx.IsSet("id") && ctx.IsSet("name") {
-		return fmt.Errorf("only one of --id or --name can be passed")
+	vs, err := tfc.resolveVarSet(ctx, ctx.String("id"), ctx.String("name"), include)
+	if err != nil {
+		return err
+	}
+
+	pp, err := json.MarshalIndent(vs, "", "    ")
+	if err != nil {
+		return nil
 	}
 
-	opts := &tfe.VariableSetListOptions{}
+	fmt.Println(string(pp))
 
-	include := ctx.StringSlice("include")
+	return nil
+}
 
-	for _, r := range include {
-		if opt, ok := varSetIncludeOpts[r]; !ok {
-			return fmt.Errorf("include opt not recognized: %s", r)
-		} else {
-			if opts.Include == "" {
-				opts.Include = string(opt)
-				continue
+var errVarSetNotFound = errors.New("variable set with matching name not found")
+
+// resolveVarSet finds a single variable set by id or name. An id is read directly, a name is
+// matched exactly against every page of the organization's variable sets and must be unique.
+func (tfc *TFCClient) resolveVarSet(ctx *cli.Context, id, name string, include []tfe.VariableSetIncludeOpt) (*tfe.VariableSet, error) {
+	if id == "" && name == "" {
+		return nil, fmt.Errorf("one of a variable set id or name is required")
+	}
+
+	if id != "" && name != "" {
+		return nil, fmt.Errorf("only one of a variable set id or name can be passed")
+	}
+
+	if id != "" {
+		logf(ctx, "reading variable set: %s", id)
+
+		vs, err := tfc.Client.VariableSets.Read(ctx.Context, id, &tfe.VariableSetReadOptions{Include: &include})
+		if err != nil {
+			return nil, fmt.Errorf("reading variable set %s: %w", id, err)
+		}
+
+		// Read only returns the variable IDs in the relationship, so fetch the variables themselves
+		for _, opt := range include {
+			if opt == tfe.VariableSetVars {
+				if vs.Variables, err = tfc.listVarSetVariables(ctx.Context, vs.ID); err != nil {
+					return nil, err
+				}
 			}
-			opts.Include = strings.Join([]string{opts.Include, string(opt)}, ",")
 		}
+
+		return vs, nil
 	}
 
-	vsl, err := tfc.Client.VariableSets.List(ctx.Context, tfc.Cfg.OrgName, opts)
+	logf(ctx, "looking for variable set named: %s", name)
+
+	all, err := tfc.listVarSets(ctx.Context, include)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	r := vsl.Items
+	var matches []*tfe.VariableSet
+	for _, vs := range all {
+		if vs.Name == name {
+			matches = append(matches, vs)
+		}
+	}
 
-	if ctx.IsSet("id") {
-		r = []*tfe.VariableSet{}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", errVarSetNotFound, name)
+	case 1:
+		logf(ctx, "variable set name match: %s (%s)", matches[0].Name, matches[0].ID)
+		return matches[0], nil
+	default:
+		ids := make([]string, len(matches))
+		for i := range matches {
+			ids[i] = matches[i].ID
+		}
+		return nil, fmt.Errorf("variable set name %s is ambiguous, pass one of these ids instead: %s", name, strings.Join(ids, ", "))
+	}
+}
 
-		for _, vs := range vsl.Items {
-			if vs.ID == ctx.String("id") {
-				r = append(r, vs)
-			}
+// listVarSets returns every variable set in the organization, walking all pages.
+func (tfc *TFCClient) listVarSets(ctx context.Context, include []tfe.VariableSetIncludeOpt) ([]*tfe.VariableSet, error) {
+	opts := &tfe.VariableSetListOptions{
+		ListOptions: tfe.ListOptions{PageSize: 100},
+		Include:     varSetIncludeString(include),
+	}
+
+	var all []*tfe.VariableSet
+	for {
+		vsl, err := tfc.Client.VariableSets.List(ctx, tfc.Cfg.OrgName, opts)
+		if err != nil {
+			return nil, err
 		}
+
+		all = append(all, vsl.Items...)
+
+		if vsl.Pagination == nil || vsl.CurrentPage >= vsl.TotalPages {
+			return all, nil
+		}
+		opts.PageNumber = vsl.NextPage
 	}
+}
 
-	if ctx.IsSet("name") {
-		r = []*tfe.VariableSet{}
+// listVarSetVariables returns every variable in a variable set, walking all pages.
+func (tfc *TFCClient) listVarSetVariables(ctx context.Context, varSetID string) ([]*tfe.VariableSetVariable, error) {
+	opts := &tfe.VariableSetVariableListOptions{ListOptions: tfe.ListOptions{PageSize: 100}}
 
-		for _, vs := range vsl.Items {
-			if vs.Name == ctx.String("name") {
-				r = append(r, vs)
-			}
+	var all []*tfe.VariableSetVariable
+	for {
+		vl, err := tfc.Client.VariableSetVariables.List(ctx, varSetID, opts)
+		if err != nil {
+			return nil, err
 		}
+
+		all = append(all, vl.Items...)
+
+		if vl.Pagination == nil || vl.CurrentPage >= vl.TotalPages {
+			return all, nil
+		}
+		opts.PageNumber = vl.NextPage
 	}
+}
 
-	pp, err := json.MarshalIndent(r, "", "    ")
-	if err != nil {
-		return nil
+func parseVarSetIncludes(include []string) ([]tfe.VariableSetIncludeOpt, error) {
+	opts := make([]tfe.VariableSetIncludeOpt, 0, len(include))
+
+	for _, r := range include {
+		opt, ok := varSetIncludeOpts[r]
+		if !ok {
+			return nil, fmt.Errorf("include opt not recognized: %s", r)
+		}
+		opts = append(opts, opt)
 	}
 
-	fmt.Println(string(pp))
+	return opts, nil
+}
 
-	return nil
+func varSetIncludeString(include []tfe.VariableSetIncludeOpt) string {
+	r := make([]string, len(include))
+	for i := range include {
+		r[i] = string(include[i])
+	}
+	return strings.Join(r, ",")
 }
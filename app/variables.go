@@ -0,0 +1,264 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+// Variables describes all the variable related methods that the Terraform
+// Enterprise API supports.
+//
+// TFE API docs: https://www.terraform.io/docs/cloud/api/workspace-variables.html
+//
+//	// List all the variables associated with the given workspace.
+//	List(ctx context.Context, workspaceID string, options *VariableListOptions) (*VariableList, error)
+//
+//	// Create is used to create a new variable.
+//	Create(ctx context.Context, workspaceID string, options VariableCreateOptions) (*Variable, error)
+//
+//	// Read a variable by its ID.
+//	Read(ctx context.Context, workspaceID string, variableID string) (*Variable, error)
+//
+//	// Update values of an existing variable.
+//	Update(ctx context.Context, workspaceID string, variableID string, options VariableUpdateOptions) (*Variable, error)
+//
+//	// Delete a variable by its ID.
+//	Delete(ctx context.Context, workspaceID string, variableID string) error
+
+// orgVariable is a workspace or variable set variable along with the owner it was found on.
+type orgVariable struct {
+	ID          string
+	Key         string
+	Value       string
+	Description string
+	Category    tfe.CategoryType
+	HCL         bool
+	Sensitive   bool
+
+	WorkspaceID   string
+	WorkspaceName string
+	VarSetID      string
+	VarSetName    string
+}
+
+func (v *orgVariable) owner() string {
+	if v.WorkspaceID != "" {
+		return "workspace " + v.WorkspaceName
+	}
+	return "variable set " + v.VarSetName
+}
+
+func concurrencyFlag() cli.Flag {
+	return &cli.IntFlag{
+		Name:  "concurrency",
+		Usage: "The number of API requests made at once.",
+		Value: 8,
+	}
+}
+
+func (tfc *TFCClient) VariablesWhereCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "where",
+		Usage:     "Find every workspace and variable set defining a variable key.",
+		UsageText: "tfc-client variables where [options] <key-or-regex>",
+		Category:  "variables",
+		Action:    tfc.variablesWhere,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "ignore-case",
+				Aliases: []string{"i"},
+				Usage:   "Match keys case insensitively.",
+			},
+			concurrencyFlag(),
+		},
+	}
+}
+
+type variableWhereResponse struct {
+	Key           string
+	Category      string
+	Workspace     string `json:",omitempty"`
+	WorkspaceID   string `json:",omitempty"`
+	VariableSet   string `json:",omitempty"`
+	VariableSetID string `json:",omitempty"`
+	Sensitive     bool
+	HCL           bool
+	ValueHash     string `json:",omitempty"`
+}
+
+func (tfc *TFCClient) variablesWhere(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("expected exactly one key or regex argument")
+	}
+
+	// The whole key has to match, so a plain key name only finds that key
+	expr := "^(?:" + ctx.Args().First() + ")$"
+	if ctx.Bool("ignore-case") {
+		expr = "(?i)" + expr
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid key regex: %w", err)
+	}
+
+	vars, err := tfc.scanOrgVariables(ctx, ctx.Int("concurrency"))
+	if err != nil {
+		return err
+	}
+
+	response := []variableWhereResponse{}
+	for _, v := range vars {
+		if !re.MatchString(v.Key) {
+			continue
+		}
+
+		r := variableWhereResponse{
+			Key:           v.Key,
+			Category:      string(v.Category),
+			Workspace:     v.WorkspaceName,
+			WorkspaceID:   v.WorkspaceID,
+			VariableSet:   v.VarSetName,
+			VariableSetID: v.VarSetID,
+			Sensitive:     v.Sensitive,
+			HCL:           v.HCL,
+		}
+
+		if !v.Sensitive {
+			r.ValueHash = valueHash(v.Value)
+		}
+
+		response = append(response, r)
+	}
+
+	// Grouping equal values together makes duplicates and drift easy to spot
+	sort.Slice(response, func(i, j int) bool {
+		a, b := response[i], response[j]
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		if a.ValueHash != b.ValueHash {
+			return a.ValueHash < b.ValueHash
+		}
+		return a.Workspace+a.VariableSet < b.Workspace+b.VariableSet
+	})
+
+	r, err := json.MarshalIndent(response, "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+	return nil
+}
+
+// scanOrgVariables lists the variables of every workspace and variable set in the organization.
+func (tfc *TFCClient) scanOrgVariables(ctx *cli.Context, concurrency int) ([]*orgVariable, error) {
+	workspaces, err := tfc.listWorkspaces(ctx.Context, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	varSets, err := tfc.listVarSets(ctx.Context, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	logf(ctx, "scanning variables of %d workspaces and %d variable sets", len(workspaces), len(varSets))
+
+	var (
+		mu   sync.Mutex
+		vars []*orgVariable
+	)
+
+	err = forEachParallel(ctx.Context, concurrency, workspaces, func(c context.Context, ws *tfe.Workspace) error {
+		wv, err := tfc.listWorkspaceVariables(c, ws.ID)
+		if err != nil {
+			return fmt.Errorf("listing variables of workspace %s: %w", ws.Name, err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, v := range wv {
+			vars = append(vars, &orgVariable{
+				ID:            v.ID,
+				Key:           v.Key,
+				Value:         v.Value,
+				Description:   v.Description,
+				Category:      v.Category,
+				HCL:           v.HCL,
+				Sensitive:     v.Sensitive,
+				WorkspaceID:   ws.ID,
+				WorkspaceName: ws.Name,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = forEachParallel(ctx.Context, concurrency, varSets, func(c context.Context, vs *tfe.VariableSet) error {
+		vsv, err := tfc.listVarSetVariables(c, vs.ID)
+		if err != nil {
+			return fmt.Errorf("listing variables of variable set %s: %w", vs.Name, err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, v := range vsv {
+			vars = append(vars, &orgVariable{
+				ID:          v.ID,
+				Key:         v.Key,
+				Value:       v.Value,
+				Description: v.Description,
+				Category:    v.Category,
+				HCL:         v.HCL,
+				Sensitive:   v.Sensitive,
+				VarSetID:    vs.ID,
+				VarSetName:  vs.Name,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
+
+// listWorkspaceVariables returns every variable of a workspace, walking all pages.
+func (tfc *TFCClient) listWorkspaceVariables(ctx context.Context, workspaceID string) ([]*tfe.Variable, error) {
+	opts := &tfe.VariableListOptions{ListOptions: tfe.ListOptions{PageSize: 100}}
+
+	var all []*tfe.Variable
+	for {
+		vl, err := tfc.Client.Variables.List(ctx, workspaceID, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, vl.Items...)
+
+		if vl.Pagination == nil || vl.CurrentPage >= vl.TotalPages {
+			return all, nil
+		}
+		opts.PageNumber = vl.NextPage
+	}
+}
+
+// valueHash identifies a value without printing it.
+func valueHash(v string) string {
+	sum := sha256.Sum256([]byte(v))
+	return hex.EncodeToString(sum[:])[:16]
+}
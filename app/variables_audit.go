@@ -0,0 +1,298 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+var auditSeverities = map[string]int{
+	"low":    1,
+	"medium": 2,
+	"high":   3,
+}
+
+// auditRule flags a non-sensitive variable when every condition it sets matches.
+type auditRule struct {
+	Name       string  `json:"name"`
+	Severity   string  `json:"severity"`
+	Key        string  `json:"key,omitempty"`      // case insensitive glob (only * is special) matched against the key
+	Value      string  `json:"value,omitempty"`    // regex matched against the value
+	Category   string  `json:"category,omitempty"` // terraform or env
+	MinLength  int     `json:"min-length,omitempty"`
+	MinEntropy float64 `json:"min-entropy,omitempty"` // shannon entropy in bits per character
+
+	valueRe *regexp.Regexp
+}
+
+// defaultAuditRules are applied unless --no-default-rules is passed.
+var defaultAuditRules = []auditRule{
+	{Name: "aws-access-key-id", Severity: "high", Value: `\b(AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{Name: "github-token", Severity: "high", Value: `\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`},
+	{Name: "terraform-cloud-token", Severity: "high", Value: `\b[A-Za-z0-9]{14}\.atlasv1\.[A-Za-z0-9_-]{60,}`},
+	{Name: "slack-token", Severity: "high", Value: `\bxox[abposr]-[A-Za-z0-9-]{10,}`},
+	{Name: "private-key", Severity: "high", Value: `-----BEGIN [A-Z ]*PRIVATE KEY-----`},
+	{Name: "secret-key-name", Severity: "medium", Key: "*SECRET*", MinLength: 1},
+	{Name: "token-key-name", Severity: "medium", Key: "*TOKEN*", MinLength: 1},
+	{Name: "password-key-name", Severity: "medium", Key: "*PASSWORD*", MinLength: 1},
+	{Name: "passwd-key-name", Severity: "medium", Key: "*PASSWD*", MinLength: 1},
+	{Name: "private-key-name", Severity: "medium", Key: "*PRIVATE_KEY*", MinLength: 1},
+	{Name: "api-key-name", Severity: "medium", Key: "*API_KEY*", MinLength: 1},
+	{Name: "credentials-key-name", Severity: "medium", Key: "*CREDENTIALS*", MinLength: 1},
+	{Name: "high-entropy-value", Severity: "low", MinLength: 20, MinEntropy: 4.0},
+}
+
+func (tfc *TFCClient) VariablesAuditCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "audit",
+		Usage:    "Scan non-sensitive workspace and variable set variables for values that look like secrets.",
+		Category: "variables",
+		Action:   tfc.variablesAudit,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "rules",
+				Usage: "JSON file with a list of extra rules. Each rule has a name, severity (low, medium, high) at least one of key (glob), value (regex) or min-entropy, and optionally category and min-length.",
+			},
+			&cli.BoolFlag{
+				Name:  "no-default-rules",
+				Usage: "Only apply the rules from --rules.",
+			},
+			&cli.StringFlag{
+				Name:  "min-severity",
+				Usage: "Only report findings of at least this severity (low, medium, high).",
+				Value: "low",
+			},
+			&cli.BoolFlag{
+				Name:  "fix",
+				Usage: "Mark every reported variable as sensitive. This can't be undone from the API.",
+			},
+			concurrencyFlag(),
+		},
+	}
+}
+
+type auditFinding struct {
+	Severity      string
+	Rules         []string
+	Key           string
+	Category      string
+	Workspace     string `json:",omitempty"`
+	WorkspaceID   string `json:",omitempty"`
+	VariableSet   string `json:",omitempty"`
+	VariableSetID string `json:",omitempty"`
+	Fixed         bool   `json:",omitempty"`
+
+	variable *orgVariable
+}
+
+func (tfc *TFCClient) variablesAudit(ctx *cli.Context) error {
+	minSeverity, ok := auditSeverities[ctx.String("min-severity")]
+	if !ok {
+		return fmt.Errorf("severity not recognized: %s", ctx.String("min-severity"))
+	}
+
+	rules, err := loadAuditRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	vars, err := tfc.scanOrgVariables(ctx, ctx.Int("concurrency"))
+	if err != nil {
+		return err
+	}
+
+	findings := []*auditFinding{}
+	for _, v := range vars {
+		if v.Sensitive {
+			continue
+		}
+
+		f := auditVariable(v, rules)
+		if f == nil || auditSeverities[f.Severity] < minSeverity {
+			continue
+		}
+
+		findings = append(findings, f)
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Severity != b.Severity {
+			return auditSeverities[a.Severity] > auditSeverities[b.Severity]
+		}
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return a.Workspace+a.VariableSet < b.Workspace+b.VariableSet
+	})
+
+	if ctx.Bool("fix") {
+		for _, f := range findings {
+			if err := tfc.markSensitive(ctx, f.variable); err != nil {
+				return fmt.Errorf("marking %s sensitive in %s: %w", f.Key, f.variable.owner(), err)
+			}
+
+			logf(ctx, "marked %s sensitive in %s", f.Key, f.variable.owner())
+			f.Fixed = true
+		}
+	}
+
+	r, err := json.MarshalIndent(findings, "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+	return nil
+}
+
+func (tfc *TFCClient) markSensitive(ctx *cli.Context, v *orgVariable) error {
+	if v.WorkspaceID != "" {
+		_, err := tfc.Client.Variables.Update(ctx.Context, v.WorkspaceID, v.ID, tfe.VariableUpdateOptions{
+			Sensitive: ptrBool(true),
+		})
+		return err
+	}
+
+	_, err := tfc.Client.VariableSetVariables.Update(ctx.Context, v.VarSetID, v.ID, &tfe.VariableSetVariableUpdateOptions{
+		Sensitive: ptrBool(true),
+	})
+	return err
+}
+
+func loadAuditRules(ctx *cli.Context) ([]auditRule, error) {
+	var rules []auditRule
+	if !ctx.Bool("no-default-rules") {
+		rules = append(rules, defaultAuditRules...)
+	}
+
+	if ctx.IsSet("rules") {
+		b, err := readInput(ctx.String("rules"))
+		if err != nil {
+			return nil, err
+		}
+
+		var extra []auditRule
+		if err := json.Unmarshal(b, &extra); err != nil {
+			return nil, fmt.Errorf("invalid rules file: %w", err)
+		}
+		rules = append(rules, extra...)
+	}
+
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("no audit rules to apply")
+	}
+
+	for i := range rules {
+		r := &rules[i]
+		if _, ok := auditSeverities[r.Severity]; !ok {
+			return nil, fmt.Errorf("rule %s: severity not recognized: %s", r.Name, r.Severity)
+		}
+
+		// A rule without a condition on the key or value would flag every variable, or every
+		// variable of a category
+		if r.Key == "" && r.Value == "" && r.MinEntropy <= 0 {
+			return nil, fmt.Errorf("rule %s: needs a key, value or min-entropy condition", r.Name)
+		}
+
+		if r.Category != "" {
+			if _, err := parseCategory(r.Category); err != nil {
+				return nil, fmt.Errorf("rule %s: %w", r.Name, err)
+			}
+		}
+
+		if r.Value != "" {
+			re, err := regexp.Compile(r.Value)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: invalid value regex: %w", r.Name, err)
+			}
+			r.valueRe = re
+		}
+	}
+
+	return rules, nil
+}
+
+// auditVariable returns a finding with every rule the variable matches, or nil if none do.
+func auditVariable(v *orgVariable, rules []auditRule) *auditFinding {
+	var f *auditFinding
+
+	for i := range rules {
+		if !rules[i].matches(v) {
+			continue
+		}
+
+		if f == nil {
+			f = &auditFinding{
+				Key:           v.Key,
+				Category:      string(v.Category),
+				Workspace:     v.WorkspaceName,
+				WorkspaceID:   v.WorkspaceID,
+				VariableSet:   v.VarSetName,
+				VariableSetID: v.VarSetID,
+				variable:      v,
+			}
+		}
+
+		f.Rules = append(f.Rules, rules[i].Name)
+		if auditSeverities[rules[i].Severity] > auditSeverities[f.Severity] {
+			f.Severity = rules[i].Severity
+		}
+	}
+
+	return f
+}
+
+func (r *auditRule) matches(v *orgVariable) bool {
+	if r.Category != "" && r.Category != string(v.Category) {
+		return false
+	}
+
+	if r.Key != "" {
+		if !globMatch(strings.ToUpper(r.Key), strings.ToUpper(v.Key)) {
+			return false
+		}
+	}
+
+	if len(v.Value) < r.MinLength {
+		return false
+	}
+
+	if r.valueRe != nil && !r.valueRe.MatchString(v.Value) {
+		return false
+	}
+
+	if r.MinEntropy > 0 && shannonEntropy(v.Value) < r.MinEntropy {
+		return false
+	}
+
+	return true
+}
+
+// shannonEntropy returns the average number of bits of information per character of s.
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := map[rune]int{}
+	n := 0
+	for _, c := range s {
+		counts[c]++
+		n++
+	}
+
+	var e float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		e -= p * math.Log2(p)
+	}
+
+	return e
+}
@@ -0,0 +1,346 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-tfe"
+	"github.com/urfave/cli/v2"
+)
+
+func (tfc *TFCClient) VariablesDiffCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "diff",
+		Usage: "Compare the variables of two variable sets or workspaces.",
+		UsageText: "tfc-client variables diff [options] <A> <B>\n" +
+			"A and B are workspace or variable set names or ids. Prefix a name with ws: or varset: when both a workspace and a variable set share it.",
+		Category: "variables",
+		Action:   tfc.variablesDiff,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "show-identical",
+				Usage: "Include the keys that are identical on both sides.",
+			},
+			&cli.StringFlag{
+				Name:  "export-missing-in",
+				Usage: "a or b; print the variables missing from that side as JSON accepted by var-set-variables upsert --json instead of the diff. Sensitive values can't be read and are left out.",
+			},
+		},
+	}
+}
+
+// variableDiffEntry is one side of a compared variable, in the same shape var-set-variables --json accepts.
+type variableDiffEntry struct {
+	Key         string  `json:"key"`
+	Value       *string `json:"value,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Category    *string `json:"category,omitempty"`
+	HCL         *bool   `json:"hcl,omitempty"`
+	Sensitive   *bool   `json:"sensitive,omitempty"`
+}
+
+type variableDiffChange struct {
+	Key         string
+	Differences []string
+	// Value is changed, or unknown when either side is sensitive
+	Value string `json:",omitempty"`
+	A     variableDiffEntry
+	B     variableDiffEntry
+}
+
+type variableDiffResponse struct {
+	A       string
+	B       string
+	OnlyInA []variableDiffEntry
+	OnlyInB []variableDiffEntry
+	Changed []variableDiffChange
+	// ValueUnknown lists the keys that match apart from a sensitive value that can't be compared
+	ValueUnknown []string `json:",omitempty"`
+	Identical    []string `json:",omitempty"`
+}
+
+func (tfc *TFCClient) variablesDiff(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		return fmt.Errorf("expected two workspaces or variable sets to compare")
+	}
+
+	aName, aVars, err := tfc.variablesOf(ctx, ctx.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	bName, bVars, err := tfc.variablesOf(ctx, ctx.Args().Get(1))
+	if err != nil {
+		return err
+	}
+
+	d := diffVariables(aVars, bVars)
+	d.A, d.B = aName, bName
+
+	if !ctx.Bool("show-identical") {
+		d.Identical = nil
+	}
+
+	var out interface{} = d
+
+	if ctx.IsSet("export-missing-in") {
+		var missing []variableDiffEntry
+		switch ctx.String("export-missing-in") {
+		case "a":
+			missing = d.OnlyInB
+		case "b":
+			missing = d.OnlyInA
+		default:
+			return fmt.Errorf("--export-missing-in must be a or b")
+		}
+
+		for i := range missing {
+			if missing[i].Value == nil {
+				logf(ctx, "%s is sensitive, its value has to be set by hand", missing[i].Key)
+			}
+		}
+		out = missing
+	}
+
+	r, err := json.MarshalIndent(out, "", "    ")
+	if err != nil {
+		return nil
+	}
+
+	fmt.Println(string(r))
+	return nil
+}
+
+// variablesOf returns a label and the variables of the workspace or variable set named by ref.
+func (tfc *TFCClient) variablesOf(ctx *cli.Context, ref string) (string, []*orgVariable, error) {
+	// Names can contain ":", so only a known prefix is split off
+	kind, nameOrID := "", ref
+	if k, rest, ok := strings.Cut(ref, ":"); ok {
+		switch k {
+		case "ws", "workspace", "varset", "set":
+			kind, nameOrID = k, rest
+		}
+	}
+
+	switch {
+	case kind == "" && looksLikeID("ws-", nameOrID):
+		kind = "ws"
+	case kind == "" && looksLikeID("varset-", nameOrID):
+		kind = "varset"
+	}
+
+	var (
+		ws     *tfe.Workspace
+		varSet *tfe.VariableSet
+		err    error
+	)
+
+	switch kind {
+	case "ws", "workspace":
+		if ws, err = tfc.resolveWorkspace(ctx.Context, nameOrID); err != nil {
+			return "", nil, err
+		}
+	case "varset", "set":
+		if varSet, err = tfc.resolveVarSetNameOrID(ctx, nameOrID); err != nil {
+			return "", nil, err
+		}
+	case "":
+		// A bare name could be either, so it has to be exactly one of them
+		ws, err = tfc.resolveWorkspace(ctx.Context, nameOrID)
+		if err != nil && !errors.Is(err, tfe.ErrResourceNotFound) {
+			return "", nil, err
+		}
+
+		varSet, err = tfc.resolveVarSetNameOrID(ctx, nameOrID)
+		if err != nil && !errors.Is(err, errVarSetNotFound) {
+			return "", nil, err
+		}
+
+		if ws != nil && varSet != nil {
+			return "", nil, fmt.Errorf("%s is both a workspace and a variable set, prefix it with ws: or varset:", nameOrID)
+		}
+
+		if ws == nil && varSet == nil {
+			return "", nil, fmt.Errorf("no workspace or variable set named %s", nameOrID)
+		}
+	}
+
+	if ws != nil {
+		wv, err := tfc.listWorkspaceVariables(ctx.Context, ws.ID)
+		if err != nil {
+			return "", nil, err
+		}
+
+		vars := make([]*orgVariable, len(wv))
+		for i, v := range wv {
+			vars[i] = &orgVariable{
+				ID:            v.ID,
+				Key:           v.Key,
+				Value:         v.Value,
+				Description:   v.Description,
+				Category:      v.Category,
+				HCL:           v.HCL,
+				Sensitive:     v.Sensitive,
+				WorkspaceID:   ws.ID,
+				WorkspaceName: ws.Name,
+			}
+		}
+
+		return fmt.Sprintf("workspace %s (%s)", ws.Name, ws.ID), vars, nil
+	}
+
+	vars := make([]*orgVariable, len(varSet.Variables))
+	for i, v := range varSet.Variables {
+		vars[i] = &orgVariable{
+			ID:          v.ID,
+			Key:         v.Key,
+			Value:       v.Value,
+			Description: v.Description,
+			Category:    v.Category,
+			HCL:         v.HCL,
+			Sensitive:   v.Sensitive,
+			VarSetID:    varSet.ID,
+			VarSetName:  varSet.Name,
+		}
+	}
+
+	return fmt.Sprintf("variable set %s (%s)", varSet.Name, varSet.ID), vars, nil
+}
+
+// resolveVarSetNameOrID reads a variable set, with its variables, by ID when given one
+// ("varset-" and 16 characters), otherwise by name. A name that happens to look like an ID is
+// looked up by name when no variable set has that ID.
+func (tfc *TFCClient) resolveVarSetNameOrID(ctx *cli.Context, nameOrID string) (*tfe.VariableSet, error) {
+	include := []tfe.VariableSetIncludeOpt{tfe.VariableSetVars}
+	if looksLikeID("varset-", nameOrID) {
+		vs, err := tfc.resolveVarSet(ctx, nameOrID, "", include)
+		if !errors.Is(err, tfe.ErrResourceNotFound) {
+			return vs, err
+		}
+	}
+	return tfc.resolveVarSet(ctx, "", nameOrID, include)
+}
+
+// diffVariables compares variables by key, reporting category, hcl, sensitive, description and
+// value differences on the same key. A key that is both a terraform and an env variable on one
+// side can't be paired by key alone, so it is compared per category instead.
+func diffVariables(a, b []*orgVariable) *variableDiffResponse {
+	duplicated := map[string]bool{}
+	for _, vars := range [][]*orgVariable{a, b} {
+		seen := map[string]bool{}
+		for _, v := range vars {
+			duplicated[v.Key] = duplicated[v.Key] || seen[v.Key]
+			seen[v.Key] = true
+		}
+	}
+
+	label := func(v *orgVariable) string {
+		if duplicated[v.Key] {
+			return string(v.Category) + "/" + v.Key
+		}
+		return v.Key
+	}
+
+	index := func(vars []*orgVariable) map[string]*orgVariable {
+		m := make(map[string]*orgVariable, len(vars))
+		for _, v := range vars {
+			m[label(v)] = v
+		}
+		return m
+	}
+
+	am, bm := index(a), index(b)
+
+	keys := make([]string, 0, len(am)+len(bm))
+	for k := range am {
+		keys = append(keys, k)
+	}
+	for k := range bm {
+		if _, ok := am[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	d := &variableDiffResponse{
+		OnlyInA: []variableDiffEntry{},
+		OnlyInB: []variableDiffEntry{},
+		Changed: []variableDiffChange{},
+	}
+
+	for _, k := range keys {
+		av, inA := am[k]
+		bv, inB := bm[k]
+
+		switch {
+		case !inB:
+			d.OnlyInA = append(d.OnlyInA, diffEntry(av))
+		case !inA:
+			d.OnlyInB = append(d.OnlyInB, diffEntry(bv))
+		default:
+			var diffs []string
+			if av.Category != bv.Category {
+				diffs = append(diffs, "category")
+			}
+			if av.HCL != bv.HCL {
+				diffs = append(diffs, "hcl")
+			}
+			if av.Sensitive != bv.Sensitive {
+				diffs = append(diffs, "sensitive")
+			}
+			if av.Description != bv.Description {
+				diffs = append(diffs, "description")
+			}
+
+			// Sensitive values are write only, so they can't be compared
+			value := ""
+			switch {
+			case av.Sensitive || bv.Sensitive:
+				value = "unknown"
+			case av.Value != bv.Value:
+				value = "changed"
+				diffs = append(diffs, "value")
+			}
+
+			if len(diffs) == 0 {
+				if value == "unknown" {
+					d.ValueUnknown = append(d.ValueUnknown, k)
+				} else {
+					d.Identical = append(d.Identical, k)
+				}
+				continue
+			}
+
+			d.Changed = append(d.Changed, variableDiffChange{
+				Key:         av.Key,
+				Differences: diffs,
+				Value:       value,
+				A:           diffEntry(av),
+				B:           diffEntry(bv),
+			})
+		}
+	}
+
+	return d
+}
+
+func diffEntry(v *orgVariable) variableDiffEntry {
+	category := string(v.Category)
+	e := variableDiffEntry{
+		Key:         v.Key,
+		Description: ptrString(v.Description),
+		Category:    &category,
+		HCL:         ptrBool(v.HCL),
+		Sensitive:   ptrBool(v.Sensitive),
+	}
+
+	if !v.Sensitive {
+		value := v.Value
+		e.Value = &value
+	}
+
+	return e
+}
@@ -0,0 +1,351 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/go-tfe"
+)
+
+// Workspaces depend on each other through run triggers, where an apply in the source workspace
+// queues a run in the target, and remote state consumers, which read another workspace's outputs.
+// Both are edges from the upstream workspace to the downstream one.
+const (
+	edgeRunTrigger  = "run-trigger"
+	edgeRemoteState = "remote-state"
+)
+
+type workspaceEdge struct {
+	From string
+	To   string
+	Kind string
+}
+
+// workspaceGraph holds workspaces by ID and the edges between them.
+type workspaceGraph struct {
+	Workspaces map[string]*tfe.Workspace
+	Edges      []workspaceEdge
+}
+
+func newWorkspaceGraph() *workspaceGraph {
+	return &workspaceGraph{Workspaces: map[string]*tfe.Workspace{}}
+}
+
+func (g *workspaceGraph) name(id string) string {
+	if ws, ok := g.Workspaces[id]; ok && ws.Name != "" {
+		return ws.Name
+	}
+	return id
+}
+
+// downstream returns the IDs of the workspaces that depend on id.
+func (g *workspaceGraph) downstream(id string) []string {
+	seen := map[string]bool{}
+	var r []string
+	for _, e := range g.Edges {
+		if e.From == id && !seen[e.To] {
+			seen[e.To] = true
+			r = append(r, e.To)
+		}
+	}
+	return r
+}
+
+// upstream returns the IDs of the workspaces id depends on.
+func (g *workspaceGraph) upstream(id string) []string {
+	seen := map[string]bool{}
+	var r []string
+	for _, e := range g.Edges {
+		if e.To == id && !seen[e.From] {
+			seen[e.From] = true
+			r = append(r, e.From)
+		}
+	}
+	return r
+}
+
+// outboundEdges lists the workspaces that depend on ws. Remote state consumers only count when
+// the workspace doesn't share its state with the whole organization.
+func (tfc *TFCClient) outboundEdges(ctx context.Context, ws *tfe.Workspace) ([]workspaceEdge, error) {
+	triggers, err := tfc.listRunTriggers(ctx, ws.ID, tfe.RunTriggerOutbound)
+	if err != nil {
+		return nil, fmt.Errorf("listing the run triggers of %s: %w", ws.Name, err)
+	}
+
+	var edges []workspaceEdge
+	for _, t := range triggers {
+		if t.Workspace != nil {
+			edges = append(edges, workspaceEdge{From: ws.ID, To: t.Workspace.ID, Kind: edgeRunTrigger})
+		}
+	}
+
+	if ws.GlobalRemoteState {
+		return edges, nil
+	}
+
+	opts := &tfe.RemoteStateConsumersListOptions{ListOptions: tfe.ListOptions{PageSize: 100}}
+	for {
+		wl, err := tfc.Client.Workspaces.ListRemoteStateConsumers(ctx, ws.ID, opts)
+		if err != nil {
+			return nil, fmt.Errorf("listing the remote state consumers of %s: %w", ws.Name, err)
+		}
+
+		for _, c := range wl.Items {
+			edges = append(edges, workspaceEdge{From: ws.ID, To: c.ID, Kind: edgeRemoteState})
+		}
+
+		if wl.Pagination == nil || wl.CurrentPage >= wl.TotalPages {
+			return edges, nil
+		}
+		opts.PageNumber = wl.NextPage
+	}
+}
+
+// buildWorkspaceGraph reads the outbound edges of every workspace in roots. With follow set,
+// the workspaces they lead to are read too, until everything downstream of roots is in the graph.
+// all supplies the workspace details for the IDs the edges point to.
+func (tfc *TFCClient) buildWorkspaceGraph(ctx context.Context, concurrency int, roots []*tfe.Workspace, all []*tfe.Workspace, follow bool) (*workspaceGraph, error) {
+	byID := make(map[string]*tfe.Workspace, len(all))
+	for _, ws := range all {
+		byID[ws.ID] = ws
+	}
+
+	g := newWorkspaceGraph()
+	var mu sync.Mutex
+
+	next := roots
+	for _, ws := range roots {
+		g.Workspaces[ws.ID] = ws
+	}
+
+	for len(next) > 0 {
+		var found []*tfe.Workspace
+
+		err := forEachParallel(ctx, concurrency, next, func(c context.Context, ws *tfe.Workspace) error {
+			edges, err := tfc.outboundEdges(c, ws)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			g.Edges = append(g.Edges, edges...)
+			for _, e := range edges {
+				if _, ok := g.Workspaces[e.To]; ok {
+					continue
+				}
+
+				to, ok := byID[e.To]
+				if !ok {
+					to = &tfe.Workspace{ID: e.To}
+				}
+				g.Workspaces[e.To] = to
+
+				if follow {
+					found = append(found, to)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		next = found
+	}
+
+	g.sortEdges()
+	return g, nil
+}
+
+// addInboundTriggers adds the run triggers that point at each workspace in targets, and the
+// workspaces they come from. Remote state has no inbound listing, so only run trigger sources
+// outside the graph are found. all supplies the workspace details for the sources.
+func (tfc *TFCClient) addInboundTriggers(ctx context.Context, concurrency int, g *workspaceGraph, targets []*tfe.Workspace, all []*tfe.Workspace) error {
+	byID := make(map[string]*tfe.Workspace, len(all))
+	for _, ws := range all {
+		byID[ws.ID] = ws
+	}
+
+	seen := make(map[workspaceEdge]bool, len(g.Edges))
+	for _, e := range g.Edges {
+		seen[e] = true
+	}
+
+	var mu sync.Mutex
+	err := forEachParallel(ctx, concurrency, targets, func(c context.Context, ws *tfe.Workspace) error {
+		triggers, err := tfc.listRunTriggers(c, ws.ID, tfe.RunTriggerInbound)
+		if err != nil {
+			return fmt.Errorf("listing the run triggers of %s: %w", ws.Name, err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		for _, t := range triggers {
+			if t.Sourceable == nil {
+				continue
+			}
+
+			e := workspaceEdge{From: t.Sourceable.ID, To: ws.ID, Kind: edgeRunTrigger}
+			if seen[e] {
+				continue
+			}
+			seen[e] = true
+			g.Edges = append(g.Edges, e)
+
+			if _, ok := g.Workspaces[e.From]; !ok {
+				from, ok := byID[e.From]
+				if !ok {
+					from = &tfe.Workspace{ID: e.From, Name: t.SourceableName}
+				}
+				g.Workspaces[e.From] = from
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	g.sortEdges()
+	return nil
+}
+
+func (g *workspaceGraph) sortEdges() {
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.From != b.From {
+			return g.name(a.From) < g.name(b.From)
+		}
+		if a.To != b.To {
+			return g.name(a.To) < g.name(b.To)
+		}
+		return a.Kind < b.Kind
+	})
+}
+
+// levels orders the workspaces so every workspace comes after everything it depends on. Workspaces
+// in the same level don't depend on each other. Workspaces on or downstream of a cycle can't be
+// ordered and are returned separately.
+func (g *workspaceGraph) levels() ([][]string, []string) {
+	indegree := make(map[string]int, len(g.Workspaces))
+	for id := range g.Workspaces {
+		indegree[id] = 0
+	}
+
+	seen := map[workspaceEdge]bool{}
+	for _, e := range g.Edges {
+		key := workspaceEdge{From: e.From, To: e.To}
+		if !seen[key] {
+			seen[key] = true
+			indegree[e.To]++
+		}
+	}
+
+	var levels [][]string
+	for {
+		var level []string
+		for id, n := range indegree {
+			if n == 0 {
+				level = append(level, id)
+			}
+		}
+
+		if len(level) == 0 {
+			break
+		}
+
+		sort.Slice(level, func(i, j int) bool { return g.name(level[i]) < g.name(level[j]) })
+		levels = append(levels, level)
+
+		for _, id := range level {
+			delete(indegree, id)
+			for _, to := range g.downstream(id) {
+				indegree[to]--
+			}
+		}
+	}
+
+	var blocked []string
+	for id := range indegree {
+		blocked = append(blocked, id)
+	}
+	sort.Slice(blocked, func(i, j int) bool { return g.name(blocked[i]) < g.name(blocked[j]) })
+
+	return levels, blocked
+}
+
+// cycles returns the strongly connected components with more than one workspace, or a workspace
+// that depends on itself, using Tarjan's algorithm.
+func (g *workspaceGraph) cycles() [][]string {
+	var (
+		index   = map[string]int{}
+		low     = map[string]int{}
+		onStack = map[string]bool{}
+		stack   []string
+		next    int
+		result  [][]string
+	)
+
+	var visit func(id string)
+	visit = func(id string) {
+		index[id], low[id] = next, next
+		next++
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, to := range g.downstream(id) {
+			if _, ok := index[to]; !ok {
+				visit(to)
+				if low[to] < low[id] {
+					low[id] = low[to]
+				}
+			} else if onStack[to] && index[to] < low[id] {
+				low[id] = index[to]
+			}
+		}
+
+		if low[id] != index[id] {
+			return
+		}
+
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == id {
+				break
+			}
+		}
+
+		selfLoop := false
+		for _, to := range g.downstream(id) {
+			selfLoop = selfLoop || to == id
+		}
+
+		if len(component) > 1 || selfLoop {
+			sort.Slice(component, func(i, j int) bool { return g.name(component[i]) < g.name(component[j]) })
+			result = append(result, component)
+		}
+	}
+
+	ids := make([]string, 0, len(g.Workspaces))
+	for id := range g.Workspaces {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if _, ok := index[id]; !ok {
+			visit(id)
+		}
+	}
+
+	return result
+}
@@ -1,7 +1,9 @@
 package app
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/hashicorp/go-tfe"
 	"github.com/urfave/cli/v2"
@@ -110,3 +112,51 @@ func (tfc *TFCClient) workspacesList(ctx *cli.Context) error {
 
 	return nil
 }
+
+// listWorkspaces returns every workspace in the organization matching opts, walking all pages.
+func (tfc *TFCClient) listWorkspaces(ctx context.Context, opts *tfe.WorkspaceListOptions) ([]*tfe.Workspace, error) {
+	if opts == nil {
+		opts = &tfe.WorkspaceListOptions{}
+	}
+	opts.PageSize = 100
+
+	var all []*tfe.Workspace
+	for {
+		wl, err := tfc.Client.Workspaces.List(ctx, tfc.Cfg.OrgName, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, wl.Items...)
+
+		if wl.Pagination == nil || wl.CurrentPage >= wl.TotalPages {
+			return all, nil
+		}
+		opts.PageNumber = wl.NextPage
+	}
+}
+
+// resolveWorkspace reads a workspace by ID when given one ("ws-" and 16 characters), otherwise by
+// name. A name that happens to look like an ID is read by name when no workspace has that ID.
+func (tfc *TFCClient) resolveWorkspace(ctx context.Context, nameOrID string) (*tfe.Workspace, error) {
+	if nameOrID == "" {
+		return nil, fmt.Errorf("a workspace name or id is required")
+	}
+
+	if looksLikeID("ws-", nameOrID) {
+		ws, err := tfc.Client.Workspaces.ReadByID(ctx, nameOrID)
+		if err == nil {
+			return ws, nil
+		}
+		if !errors.Is(err, tfe.ErrResourceNotFound) {
+			return nil, fmt.Errorf("reading workspace %s: %w", nameOrID, err)
+		}
+	}
+
+	ws, err := tfc.Client.Workspaces.Read(ctx, tfc.Cfg.OrgName, nameOrID)
+	if err != nil {
+		return nil, fmt.Errorf("reading workspace %s: %w", nameOrID, err)
+	}
+
+	return ws, nil
+}
@@ -4,7 +4,9 @@ go 1.19
 
 require (
 	github.com/hashicorp/go-tfe v1.13.0
+	github.com/hashicorp/go-version v1.6.0
 	github.com/urfave/cli/v2 v2.23.5
+	golang.org/x/time v0.0.0-20190308202827-9d24e82272b4
 )
 
 require (
@@ -13,9 +15,7 @@ require (
 	github.com/hashicorp/go-cleanhttp v0.5.2 // indirect
 	github.com/hashicorp/go-retryablehttp v0.7.1 // indirect
 	github.com/hashicorp/go-slug v0.10.0 // indirect
-	github.com/hashicorp/go-version v1.6.0 // indirect
 	github.com/hashicorp/jsonapi v0.0.0-20210826224640-ee7dae0fb22d // indirect
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
 	github.com/xrash/smetrics v0.0.0-20201216005158-039620a65673 // indirect
-	golang.org/x/time v0.0.0-20190308202827-9d24e82272b4 // indirect
 )
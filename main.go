@@ -78,16 +78,133 @@ func main() {
 			Subcommands: []*cli.Command{tfc.VarSetsListCmd(), tfc.VarSetsListForWorkspaceCmd(), tfc.VarSetsReadCmd()},
 		},
 		{
-			Name:        "var-set-variables",
-			Usage:       "Interact Terraform Variable Set Variables",
-			UsageText:   "Interact Terraform Variable Set Variables\nReference: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/variable-set-variables",
-			Subcommands: []*cli.Command{tfc.VarSetVariablesListCmd(), tfc.VarSetVariablesUpdateCmd()},
+			Name:      "var-set-variables",
+			Usage:     "Interact Terraform Variable Set Variables",
+			UsageText: "Interact Terraform Variable Set Variables\nReference: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/variable-set-variables",
+			Subcommands: []*cli.Command{
+				tfc.VarSetVariablesListCmd(),
+				tfc.VarSetVariablesCreateCmd(),
+				tfc.VarSetVariablesUpdateCmd(),
+				tfc.VarSetVariablesUpsertCmd(),
+				tfc.VarSetVariablesDeleteCmd(),
+			},
 		},
 		{
-			Name:        "runs",
-			Usage:       "Interact with Terraform Cloud runs",
-			UsageText:   "Interact with Terraform Cloud runs\nReference: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/runs",
-			Subcommands: []*cli.Command{tfc.RunsCreateCmd()},
+			Name:        "variables",
+			Usage:       "Query workspace and variable set variables across the organization",
+			UsageText:   "Query workspace and variable set variables across the organization\nReference: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/workspace-variables",
+			Subcommands: []*cli.Command{tfc.VariablesWhereCmd(), tfc.VariablesAuditCmd(), tfc.VariablesDiffCmd()},
+		},
+		{
+			Name:      "state",
+			Usage:     "Interact with Terraform Cloud state versions",
+			UsageText: "Interact with Terraform Cloud state versions\nReference: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/state-versions",
+			Subcommands: []*cli.Command{
+				tfc.StateListCmd(),
+				tfc.StateShowCmd(),
+				tfc.StateDownloadCmd(),
+				tfc.StateOutputsCmd(),
+				tfc.StateSearchCmd(),
+				tfc.StateDiffCmd(),
+				tfc.StateBackupCmd(),
+				tfc.StatePushCmd(),
+			},
+		},
+		{
+			Name:        "outputs",
+			Usage:       "Export workspace outputs or run commands with them",
+			UsageText:   "Export workspace outputs or run commands with them\nReference: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/state-version-outputs",
+			Subcommands: []*cli.Command{tfc.OutputsExportCmd(), tfc.OutputsExecCmd()},
+		},
+		{
+			Name:        "inventory",
+			Usage:       "Report on what the organization's workspaces use",
+			UsageText:   "Report on what the organization's workspaces use\nReference: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/workspaces",
+			Subcommands: []*cli.Command{tfc.InventoryVersionsCmd()},
+		},
+		{
+			Name:      "teams",
+			Usage:     "Manage teams and team membership",
+			UsageText: "Manage teams and team membership\nReference: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/teams",
+			Subcommands: []*cli.Command{
+				tfc.TeamsListCmd(),
+				tfc.TeamsShowCmd(),
+				tfc.TeamsCreateCmd(),
+				tfc.TeamsUpdateCmd(),
+				tfc.TeamsDeleteCmd(),
+				tfc.TeamMembersCmd(),
+			},
+		},
+		{
+			Name:      "access",
+			Usage:     "Manage team access to workspaces",
+			UsageText: "Manage team access to workspaces\nReference: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/team-access",
+			Subcommands: []*cli.Command{
+				tfc.AccessListCmd(),
+				tfc.AccessGrantCmd(),
+				tfc.AccessRevokeCmd(),
+				tfc.AccessReportCmd(),
+			},
+		},
+		{
+			Name:      "members",
+			Usage:     "Manage organization members and invitations",
+			UsageText: "Manage organization members and invitations\nReference: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/organization-memberships",
+			Subcommands: []*cli.Command{
+				tfc.MembersListCmd(),
+				tfc.MembersShowCmd(),
+				tfc.MembersInviteCmd(),
+				tfc.MembersRemoveCmd(),
+				tfc.MembersOffboardCmd(),
+				tfc.MembersPendingCmd(),
+			},
+		},
+		{
+			Name:      "tokens",
+			Usage:     "Manage organization, team and user API tokens",
+			UsageText: "Manage organization, team and user API tokens\nReference: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/team-tokens",
+			Subcommands: []*cli.Command{
+				tfc.TokensOrgCmd(),
+				tfc.TokensTeamCmd(),
+				tfc.TokensUserCmd(),
+				tfc.TokensRotateCmd(),
+			},
+		},
+		tfc.WhoamiCmd(),
+		{
+			Name:        "org",
+			Usage:       "Inspect the organization",
+			UsageText:   "Inspect the organization\nReference: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/organizations",
+			Subcommands: []*cli.Command{tfc.OrgShowCmd(), tfc.OrgQueueCmd()},
+		},
+		{
+			Name:      "run-triggers",
+			Usage:     "Manage the run triggers between workspaces",
+			UsageText: "Manage the run triggers between workspaces\nReference: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/run-triggers",
+			Subcommands: []*cli.Command{
+				tfc.RunTriggersListCmd(),
+				tfc.RunTriggersCreateCmd(),
+				tfc.RunTriggersDeleteCmd(),
+				tfc.RunTriggersSyncCmd(),
+			},
+		},
+		{
+			Name:        "graph",
+			Usage:       "Graph the dependencies between workspaces",
+			UsageText:   "Graph the dependencies between workspaces\nReference: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/run-triggers",
+			Subcommands: []*cli.Command{tfc.GraphWorkspacesCmd()},
+		},
+		{
+			Name:      "runs",
+			Usage:     "Interact with Terraform Cloud runs",
+			UsageText: "Interact with Terraform Cloud runs\nReference: https://developer.hashicorp.com/terraform/cloud-docs/api-docs/runs",
+			Subcommands: []*cli.Command{
+				tfc.RunsCreateCmd(),
+				tfc.RunsPendingApprovalCmd(),
+				tfc.RunsApplyIfCmd(),
+				tfc.RunsCreateManyCmd(),
+				tfc.RunsOrchestrateCmd(),
+			},
 		},
 	}
 